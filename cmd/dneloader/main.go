@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+)
+
+// main loads a CSV or fixed-width DNE export with the same loader used by the
+// "dataset" provider, validating every row with dto.Cep.Validate.
+// It prints one line per invalid row and a summary, and exits with status 1
+// if the file cannot be read or any row is invalid.
+func main() {
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	path := flag.String("file", "", "CSV (.csv) or fixed-width (.txt, .dat) DNE export")
+	flag.Parse()
+	if *path == "" {
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	ds, rowErrors, err := dataset.Load(*path)
+	if err != nil {
+		slog.Info("dneloader: " + err.Error())
+		os.Exit(1)
+	}
+	for _, rowErr := range rowErrors {
+		fmt.Fprintln(os.Stderr, *path+": "+rowErr.Error())
+	}
+	slog.Info("dneloader: dataset validated", "path", *path, "ceps", ds.Len(), "invalid_rows", len(rowErrors))
+	if len(rowErrors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/loadtest"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/mockserver"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// mockedProviders are the providers pointed at the mock server, by the path of their url.
+var mockedProviders = map[string]string{
+	"brasilapi":    "/api/cep/v1/{{cep}}",
+	"brasilapi-v2": "/api/cep/v2/{{cep}}",
+	"viacep":       "/ws/{{cep}}/json/",
+}
+
+// main starts lookups at a target rate, either in-process, racing the providers
+// against a mock server started with the given scenario, or on a "serve" command
+// given by -server, and prints the latency percentiles, the winners, the errors,
+// the allocations and the goroutines. An interrupt stops starting lookups and
+// reports on the ones made.
+// It exits with status 2 if the options are invalid, such as a -rate that is not
+// positive, and with status 1 if the mock server cannot start.
+func main() {
+	rate := flag.Float64("rate", 50, "lookups started per second")
+	duration := flag.Duration("duration", 10*time.Second, "how long to start lookups for")
+	maxInFlight := flag.Int("max-inflight", 256, "lookups in flight at most; the ones due beyond it are dropped")
+	ceps := flag.String("ceps", "39408078,39408079", "comma-separated ceps looked up in turn")
+	server := flag.String("server", "", "url of a \"cep serve\" to load instead of racing in-process, e.g. http://localhost:8080")
+	providers := flag.String("providers", "brasilapi-v2,viacep", "comma-separated providers to race in-process: "+strings.Join(mockProviderNames(), ", "))
+	scenario := flag.String("scenario", "flaky", "mock server scenario ("+strings.Join(mockserver.ScenarioNames(), ", ")+") or JSON scenario file")
+	responses := flag.String("responses", "responses", "folder of the captured responses served by the mock server")
+	timeout := flag.Duration("timeout", 3*time.Second, "how long each lookup waits for the providers")
+	grace := flag.Duration("grace", 0, "grace window of the race")
+	useCache := flag.Bool("cache", false, "race an in-memory cache in front of the providers")
+	maxIdle := flag.Int("max-idle-per-host", 0, "idle connections kept per host by each provider client; 0 keeps the default")
+	delay := flag.Duration("delay", 0, "longest random delay before each HTTP query, as the CLI waits 1.5s")
+	asJson := flag.Bool("json", false, "print the report as JSON")
+	verbose := flag.Bool("v", false, "log the lookups to the standard error")
+	flag.Parse()
+
+	// !(*rate > 0) rejects a NaN rate as well.
+	if !(*rate > 0) || *duration <= 0 || *maxInFlight < 1 || *ceps == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	logTo := io.Discard
+	if *verbose {
+		logTo = os.Stderr
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(logTo, nil)))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var target loadtest.Target
+	if *server != "" {
+		target = loadtest.ServerTarget(&http.Client{}, *server)
+	} else {
+		opts, err := raceInProcess(*providers, *scenario, *responses, *useCache, *maxIdle, *grace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		usecase.SimulatedDelay = *delay
+		target = loadtest.LookupTarget(opts, *timeout)
+	}
+
+	report := loadtest.Run(ctx, loadtest.Options{
+		Rate:        *rate,
+		Duration:    *duration,
+		MaxInFlight: *maxInFlight,
+		Ceps:        strings.Split(*ceps, ","),
+	}, target)
+	if *asJson {
+		json.NewEncoder(os.Stdout).Encode(report)
+		return
+	}
+	report.Write(os.Stdout)
+}
+
+// raceInProcess starts the mock server with the scenario and points the mocked
+// providers at it, each with its own client, and returns the race options of the
+// providers, with the cache in front if useCache.
+func raceInProcess(providerList, scenarioName, responses string, useCache bool, maxIdle int, grace time.Duration) (usecase.RaceOptions, error) {
+	names, err := usecase.ParseProviders(providerList)
+	if err != nil {
+		return usecase.RaceOptions{}, err
+	}
+	scenario, err := mockserver.LoadScenario(scenarioName)
+	if err != nil {
+		return usecase.RaceOptions{}, err
+	}
+	mock, err := mockserver.New(responses, scenario)
+	if err != nil {
+		return usecase.RaceOptions{}, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return usecase.RaceOptions{}, err
+	}
+	go http.Serve(listener, mock)
+
+	for _, name := range names {
+		path, ok := mockedProviders[name]
+		if !ok {
+			return usecase.RaceOptions{}, fmt.Errorf("provider %s is not emulated by the mock server, use %s", name, strings.Join(mockProviderNames(), ", "))
+		}
+		client, err := usecase.NewClient(usecase.ClientOptions{MaxIdleConnsPerHost: maxIdle})
+		if err != nil {
+			return usecase.RaceOptions{}, err
+		}
+		usecase.ConfigureProvider(name, usecase.ProviderSettings{URL: "http://" + listener.Addr().String() + path, Client: client})
+	}
+	if useCache {
+		c, err := cache.Open(filepath.Join(os.TempDir(), "loadtest-cache.json"), 0)
+		if err != nil {
+			return usecase.RaceOptions{}, err
+		}
+		c.Clear()
+		usecase.RegisterCache(c)
+		names = append([]string{usecase.CacheProviderName}, names...)
+	}
+	return usecase.RaceOptions{Providers: names, GraceWindow: grace}, nil
+}
+
+// mockProviderNames returns the providers emulated by the mock server, sorted.
+func mockProviderNames() []string {
+	names := make([]string, 0, len(mockedProviders))
+	for name := range mockedProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
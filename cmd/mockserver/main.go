@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/mockserver"
+)
+
+// main serves the Brasilapi and ViaCEP endpoints from the captured responses, with
+// the latencies, errors and statuses of a scenario, built-in or from a JSON file,
+// so the races can be reproduced without internet. See config.mock.json for the
+// endpoints to point the CLI at.
+// It exits with status 1 if the scenario or the fixtures cannot be loaded, or the
+// address cannot be listened on.
+func main() {
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	addr := flag.String("addr", ":8081", "address to listen on")
+	responses := flag.String("responses", "responses", "folder of the captured responses")
+	scenarioName := flag.String("scenario", "brasilapi-wins",
+		"built-in scenario ("+strings.Join(mockserver.ScenarioNames(), ", ")+") or JSON scenario file")
+	flag.Parse()
+
+	scenario, err := mockserver.LoadScenario(*scenarioName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	server, err := mockserver.New(*responses, scenario)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	slog.Info("mockserver: listening", "addr", *addr, "scenario", *scenarioName)
+	httpServer := &http.Server{Addr: *addr, Handler: server, ReadHeaderTimeout: 5 * time.Second}
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
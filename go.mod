@@ -2,4 +2,7 @@ module github.com/antoniofmoliveira/fullcycle-multithreading
 
 go 1.23.2
 
-require golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c
+require (
+	golang.org/x/exp v0.0.0-20241009180824-f66d83c29e7c
+	golang.org/x/text v0.19.0
+)
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// Cache keeps the ceps resolved by the providers, so repeated lookups skip the
+// network. Entries expire after the cache's TTL. The cache lives in memory and is
+// persisted as a JSON file by Save. A Cache is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]entry
+	now     func() time.Time
+}
+
+// entry is a cached cep and the time it was stored.
+type entry struct {
+	Cep      dto.Cep   `json:"cep"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Open loads the cache persisted at path. Expired entries are ignored by Get and
+// All, and dropped by Prune and Save.
+// A missing file opens an empty cache, which Save creates.
+// A ttl of zero keeps entries forever.
+// It returns an error if the file cannot be read or is not a cache file.
+func Open(path string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{path: path, ttl: ttl, entries: map[string]entry{}, now: time.Now}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, errors.New("invalid cache file " + path + ": " + err.Error())
+	}
+	return c, nil
+}
+
+// Path returns the file the cache is persisted to.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Get returns the cached Cep for cep, which may be written with or without '-'.
+// If the cep is not cached or its entry expired, it returns an empty Cep and false.
+func (c *Cache) Get(cep string) (dto.Cep, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key(cep)]
+	if !ok || c.expired(e) {
+		return dto.Cep{}, false
+	}
+	return e.Cep, true
+}
+
+// Put stores the cep, replacing any entry for the same cep.
+func (c *Cache) Put(cep dto.Cep) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key(cep.Cep)] = entry{Cep: cep, StoredAt: c.now()}
+}
+
+// All returns the ceps of the entries not expired, sorted by cep.
+func (c *Cache) All() []dto.Cep {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ceps := make([]dto.Cep, 0, len(c.entries))
+	for _, e := range c.entries {
+		if !c.expired(e) {
+			ceps = append(ceps, e.Cep)
+		}
+	}
+	sort.Slice(ceps, func(i, j int) bool { return key(ceps[i].Cep) < key(ceps[j].Cep) })
+	return ceps
+}
+
+// Len returns the number of entries, including expired ones not pruned yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Prune removes the expired entries and returns how many were removed.
+func (c *Cache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for k, e := range c.entries {
+		if c.expired(e) {
+			delete(c.entries, k)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Clear removes all the entries.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]entry{}
+}
+
+// Save prunes the cache and writes it to its file, replacing it atomically.
+func (c *Cache) Save() error {
+	c.Prune()
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// expired reports whether the entry is older than the ttl.
+func (c *Cache) expired(e entry) bool {
+	return c.ttl > 0 && c.now().Sub(e.StoredAt) > c.ttl
+}
+
+// key returns the cep without '-', so both spellings share an entry.
+func key(cep string) string {
+	return strings.ReplaceAll(cep, "-", "")
+}
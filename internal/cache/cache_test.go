@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c, err := Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	cep, err := dto.NewCep("39408-078", "MG", "Montes Claros", "Vila Atlântida", "Rua Herlindo Silveira")
+	if err != nil {
+		t.Fatalf("NewCep() error = %v", err)
+	}
+	c.Put(*cep)
+	if got, ok := c.Get("39408078"); !ok || got.City != "Montes Claros" {
+		t.Errorf("Get() = %v, %v, want the cep", got, ok)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, err := Open(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got, ok := reopened.Get("39408-078"); !ok || got != *cep {
+		t.Errorf("Get() after Open() = %v, %v, want %v", got, ok, *cep)
+	}
+
+	reopened.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, ok := reopened.Get("39408078"); ok {
+		t.Errorf("Get() of an expired entry = true, want false")
+	}
+	if removed := reopened.Prune(); removed != 1 || reopened.Len() != 0 {
+		t.Errorf("Prune() = %d, Len() = %d, want 1 and 0", removed, reopened.Len())
+	}
+}
+
+func TestOpenInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, 0); err == nil {
+		t.Errorf("Open() error = nil, want an error")
+	}
+}
+
+func BenchmarkCache_Get(b *testing.B) {
+	c, err := Open(filepath.Join(b.TempDir(), "cache.json"), time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := range 1000 {
+		cep, err := dto.NewCep(fmt.Sprintf("39%06d", i), "MG", "Montes Claros", "Ibituruna", "Rua Herlindo Silveira")
+		if err != nil {
+			b.Fatal(err)
+		}
+		c.Put(*cep)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, ok := c.Get(fmt.Sprintf("39%06d", i%1000)); !ok {
+				b.Error("Get() = false, want the cep")
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrNotRecorded is wrapped by the error of a replayed request without a cassette.
+var ErrNotRecorded = errors.New("no cassette recorded")
+
+// maxBodySize bounds the bodies recorded; a larger response is passed on unrecorded.
+const maxBodySize = 1 << 20
+
+// Cassette is a request to a provider and its response, stored as a JSON file
+// under the provider's folder, named after the cep. Only what the queries need
+// is kept: the url without credentials, the status, the Content-Type and the body.
+type Cassette struct {
+	Provider   string    `json:"provider"`
+	Cep        string    `json:"cep"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Method     string    `json:"method"`
+	Url        string    `json:"url"`
+	Status     int       `json:"status"`
+	// ContentType is the Content-Type of the response, if any.
+	ContentType string `json:"content_type,omitempty"`
+	// Body is the body of the response, when it is UTF-8 text, or else BodyBase64.
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// BodyBytes returns the body of the response.
+func (c *Cassette) BodyBytes() ([]byte, error) {
+	if c.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(c.BodyBase64)
+	}
+	return []byte(c.Body), nil
+}
+
+// setBody stores body as text, or in base64 if it is not UTF-8, such as a Latin-1 body.
+func (c *Cassette) setBody(body []byte) {
+	if utf8.Valid(body) {
+		c.Body = string(body)
+		return
+	}
+	c.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+}
+
+// Mode tells whether a Transport records or replays.
+type Mode int
+
+const (
+	// Record sends the requests and stores each response, replacing the cassette
+	// of the same provider and cep.
+	Record Mode = iota + 1
+	// Replay answers the requests from the cassettes, without the network.
+	Replay
+)
+
+// Transport is an http.RoundTripper that records the requests of the provider
+// queries to cassettes in Dir, or replays them from there. Requests that Key
+// does not identify go to Next in both modes.
+type Transport struct {
+	Dir  string
+	Mode Mode
+	// Next sends the requests that are not replayed.
+	Next http.RoundTripper
+	// Key returns the provider and the cep of a request, such as usecase.RequestQuery.
+	Key func(req *http.Request) (provider, cep string, ok bool)
+
+	mu sync.Mutex
+}
+
+// RoundTrip records or replays the request, as told by the Mode.
+// A replayed request without a cassette fails with an error wrapping ErrNotRecorded.
+// A failure to write a cassette fails the request, so a recording is never
+// silently incomplete.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider, cep, ok := t.Key(req)
+	if !ok {
+		return t.Next.RoundTrip(req)
+	}
+	if t.Mode == Replay {
+		c, err := Load(t.Dir, provider, cep)
+		if err != nil {
+			return nil, err
+		}
+		return c.response(req)
+	}
+
+	res, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize+1))
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxBodySize {
+		res.Body = readCloser{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		return res, nil
+	}
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	c := &Cassette{
+		Provider:    provider,
+		Cep:         cep,
+		RecordedAt:  time.Now().UTC(),
+		Method:      req.Method,
+		Url:         sanitize(req.URL),
+		Status:      res.StatusCode,
+		ContentType: res.Header.Get("Content-Type"),
+	}
+	c.setBody(body)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := c.save(t.Dir); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readCloser reads from a reader and closes a closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// response builds the response of the cassette to req.
+func (c *Cassette) response(req *http.Request) (*http.Response, error) {
+	body, err := c.BodyBytes()
+	if err != nil {
+		return nil, errors.New("invalid cassette " + c.Provider + "/" + c.Cep + ": " + err.Error())
+	}
+	header := http.Header{}
+	if c.ContentType != "" {
+		header.Set("Content-Type", c.ContentType)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(c.Status) + " " + http.StatusText(c.Status),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// secretParam matches the query parameters whose values are not recorded.
+var secretParam = regexp.MustCompile(`(?i)token|key|secret|password|auth|signature`)
+
+// sanitize returns u without user and password, and with the values of the query
+// parameters that look like credentials replaced by "REDACTED".
+func sanitize(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for name := range query {
+		if secretParam.MatchString(name) {
+			query.Set(name, "REDACTED")
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// unsafeName matches the characters not kept in file names.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// path returns the file of the cassette of provider and cep in dir.
+func path(dir, provider, cep string) string {
+	return filepath.Join(dir, unsafeName.ReplaceAllString(provider, "_"), unsafeName.ReplaceAllString(cep, "_")+".json")
+}
+
+// save writes the cassette to its file in dir, replacing it atomically.
+func (c *Cassette) save(dir string) error {
+	file := path(dir, c.Provider, c.Cep)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Load returns the cassette of provider and cep in dir.
+// It returns an error wrapping ErrNotRecorded if there is none.
+func Load(dir, provider, cep string) (*Cassette, error) {
+	data, err := os.ReadFile(path(dir, provider, cep))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w for provider %s and cep %s in %s", ErrNotRecorded, provider, cep, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.New("invalid cassette " + path(dir, provider, cep) + ": " + err.Error())
+	}
+	return &c, nil
+}
+
+// List returns the cassettes in dir, sorted by provider and cep.
+func List(dir string) ([]*Cassette, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var cassettes []*Cassette
+	for _, file := range files {
+		provider := filepath.Base(filepath.Dir(file))
+		cep := strings.TrimSuffix(filepath.Base(file), ".json")
+		c, err := Load(dir, provider, cep)
+		if err != nil {
+			return nil, err
+		}
+		cassettes = append(cassettes, c)
+	}
+	sort.Slice(cassettes, func(i, j int) bool {
+		if cassettes[i].Provider != cassettes[j].Provider {
+			return cassettes[i].Provider < cassettes[j].Provider
+		}
+		return cassettes[i].Cep < cassettes[j].Cep
+	})
+	return cassettes, nil
+}
@@ -0,0 +1,106 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// key identifies the requests by the provider and cep query parameters.
+func key(req *http.Request) (string, string, bool) {
+	provider, cep := req.URL.Query().Get("provider"), req.URL.Query().Get("cep")
+	return provider, cep, provider != ""
+}
+
+func get(t *testing.T, transport http.RoundTripper, url string) (int, string, string, error) {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return 0, "", "", err
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, res.Header.Get("Content-Type"), string(body), nil
+}
+
+func TestTransport(t *testing.T) {
+	latin1, err := os.ReadFile("../../responses/viacep.200.latin1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Query().Get("cep") {
+		case "39408078":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"cep": "39408078"}`))
+		case "00000000":
+			w.Header().Set("Content-Type", "application/json; charset=iso-8859-1")
+			w.Write(latin1)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	dir := t.TempDir()
+	recorder := &Transport{Dir: dir, Mode: Record, Next: http.DefaultTransport, Key: key}
+	base := strings.Replace(server.URL, "http://", "http://user:pass@", 1)
+	urls := []string{
+		base + "/?provider=viacep&cep=39408078&token=abc",
+		base + "/?provider=viacep&cep=00000000",
+		base + "/?provider=brasilapi&cep=39408079",
+	}
+	var recorded []string
+	for _, url := range urls {
+		status, contentType, body, err := get(t, recorder, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded = append(recorded, strings.Join([]string{http.StatusText(status), contentType, body}, "|"))
+	}
+	if _, _, _, err := get(t, recorder, server.URL+"/?cep=39408078"); err != nil {
+		t.Errorf("RoundTrip() of a request without key error = %v", err)
+	}
+	server.Close()
+
+	c, err := Load(dir, "viacep", "39408078")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(c.Url, "pass") || strings.Contains(c.Url, "abc") || !strings.Contains(c.Url, "token=REDACTED") {
+		t.Errorf("recorded url = %q, want it without credentials", c.Url)
+	}
+	if c, _ := Load(dir, "viacep", "00000000"); c.BodyBase64 == "" {
+		t.Errorf("Latin-1 body recorded as %q, want it in base64", c.Body)
+	}
+
+	replayer := &Transport{Dir: dir, Mode: Replay, Key: key}
+	for i, url := range urls {
+		status, contentType, body, err := get(t, replayer, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join([]string{http.StatusText(status), contentType, body}, "|"); got != recorded[i] {
+			t.Errorf("replayed %q, want %q", got, recorded[i])
+		}
+	}
+	if _, _, _, err := get(t, replayer, base+"/?provider=viacep&cep=01001000"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("RoundTrip() error = %v, want ErrNotRecorded", err)
+	}
+
+	cassettes, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range cassettes {
+		names = append(names, c.Provider+"/"+c.Cep)
+	}
+	if got := strings.Join(names, ","); got != "brasilapi/39408079,viacep/00000000,viacep/39408078" {
+		t.Errorf("List() = %s", got)
+	}
+}
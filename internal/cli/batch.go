@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// batchLine is the output line of a cep in batch: the winner or the error, with
+// the exit status lookup would have for the cep.
+type batchLine struct {
+	Input  string           `json:"input"`
+	Result *usecase.Result  `json:"result,omitempty"`
+	Error  *dto.ErrorDetail `json:"error,omitempty"`
+	Status int              `json:"status"`
+}
+
+// runBatch runs "batch [flags] [FILE]", which resolves the ceps of FILE, or of the
+// standard input, one per line, with -workers lookups at a time. Blank lines and
+// lines starting with '#' are skipped. It writes one JSON line per cep, in the
+// order of the input, and logs to the standard error.
+// It returns ExitOk if every cep has a winner, or else the status of the first cep
+// without winner, such as ExitNotFound. A SIGINT, SIGTERM or SIGHUP stops reading
+// the input and cancels the lookups; the lines of the ceps already read are still
+// written, and it returns ExitInterrupted. A second signal force-quits.
+func runBatch(args []string, s *streams) int {
+	fs := newFlagSet("batch", "[FILE]", "Resolves the ceps of FILE, or of the standard input, one per line, and writes one JSON line per cep.", s)
+	workers := fs.Int("workers", 4, "number of ceps resolved at a time")
+	rf := addRaceFlags(fs)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() > 1 || *workers < 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	input := s.stdin
+	if fs.NArg() == 1 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(s.stderr, err)
+			return ExitFailure
+		}
+		defer f.Close()
+		input = f
+	}
+
+	sess, err := rf.newSession(s.stderr)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	ctx, finish, interrupted := notifySession(sess)
+	defer finish()
+	out := bufio.NewWriter(s.stdout)
+	status, err := batch(ctx, sess, input, out, *workers)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if interrupted() {
+		return ExitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	return status
+}
+
+// batch resolves the ceps read from r with up to workers lookups at a time and writes
+// their lines to w in the order of the input. It returns the status of the first cep
+// without winner, or ExitOk, and the error reading r or writing w.
+// When ctx is done it stops reading r, which may be a terminal blocked in a read,
+// and writes only the lines of the lookups already started.
+func batch(ctx context.Context, sess *session, r io.Reader, w io.Writer, workers int) (int, error) {
+	lines := make(chan chan batchLine, workers)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var readErr error
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			cep := strings.TrimSpace(scanner.Text())
+			if cep == "" || strings.HasPrefix(cep, "#") {
+				continue
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			line := make(chan batchLine, 1)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				line <- lookupLine(ctx, sess, cep)
+			}()
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = scanner.Err()
+		wg.Wait()
+	}()
+
+	status := ExitOk
+	enc := json.NewEncoder(w)
+	var writeErr error
+	write := func(line chan batchLine) {
+		l := <-line
+		if status == ExitOk {
+			status = l.Status
+		}
+		if writeErr == nil {
+			writeErr = enc.Encode(l)
+		}
+	}
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if readErr != nil {
+					return status, readErr
+				}
+				return status, writeErr
+			}
+			write(line)
+		case <-ctx.Done():
+			for {
+				select {
+				case line, ok := <-lines:
+					if ok {
+						write(line)
+						continue
+					}
+				default:
+				}
+				return status, writeErr
+			}
+		}
+	}
+}
+
+// lookupLine resolves one cep within the configured timeout.
+func lookupLine(ctx context.Context, sess *session, cep string) batchLine {
+	ctx, cancel := context.WithTimeout(ctx, sess.cfg.Timeout.Duration)
+	defer cancel()
+	result, err := usecase.Lookup(ctx, cep, sess.opts)
+	if err != nil {
+		return batchLine{Input: cep, Error: dto.NewErrorDetail(err, ""), Status: exitStatus(err)}
+	}
+	return batchLine{Input: cep, Result: &result}
+}
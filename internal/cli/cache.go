@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// runCache runs "cache [flags] stats|get CEP|prune|clear" on the cache file of the
+// config, or the one given by -path:
+//
+//	stats    prints the path and the number of entries, live and expired
+//	get CEP  prints the cached cep as JSON
+//	prune    removes the expired entries
+//	clear    removes all the entries
+//
+// Entries older than the ttl of the config are expired.
+// It returns ExitFailure if the cache cannot be read or written, or the cep is not cached.
+func runCache(args []string, s *streams) int {
+	fs := newFlagSet("cache", "stats|get CEP|prune|clear", "Inspects and maintains the cache of resolved ceps.", s)
+	configPath := fs.String("config", "", "JSON config file (default $CEP_CONFIG)")
+	path := fs.String("path", "", "cache file; by default the path of the config")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	action := fs.Arg(0)
+	if wantArgs := map[string]int{"stats": 1, "get": 2, "prune": 1, "clear": 1}[action]; wantArgs == 0 || fs.NArg() != wantArgs {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	if *path == "" {
+		*path = cfg.Cache.Path
+	}
+	c, err := cache.Open(*path, cfg.Cache.Ttl.Duration)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	switch action {
+	case "stats":
+		live := len(c.All())
+		fmt.Fprintf(s.stdout, "path: %s\nentries: %d\nexpired: %d\n", c.Path(), live, c.Len()-live)
+		return ExitOk
+	case "get":
+		found, ok := c.Get(fs.Arg(1))
+		if !ok {
+			fmt.Fprintln(s.stderr, "not cached: "+fs.Arg(1))
+			return ExitFailure
+		}
+		if err := writeJsonLines(s.stdout, []dto.Cep{found}); err != nil {
+			fmt.Fprintln(s.stderr, err)
+			return ExitFailure
+		}
+		return ExitOk
+	case "prune":
+		fmt.Fprintf(s.stdout, "removed: %d\n", c.Prune())
+	case "clear":
+		c.Clear()
+	}
+	if err := c.Save(); err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	return ExitOk
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cassette"
+)
+
+// runCassette runs "cassette [flags] list|body PROVIDER CEP" on the cassettes
+// recorded with -record in the folder given by -dir:
+//
+//	list               prints the provider, cep, status and recording time of each cassette
+//	body PROVIDER CEP  prints the recorded response body, as the fixtures of responses/ keep it
+//
+// It returns ExitFailure if the folder cannot be read or the cassette does not exist.
+func runCassette(args []string, s *streams) int {
+	fs := newFlagSet("cassette", "list|body PROVIDER CEP", "Inspects the provider responses recorded with -record.", s)
+	dir := fs.String("dir", "cassettes", "folder of the cassettes")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	action := fs.Arg(0)
+	if wantArgs := map[string]int{"list": 1, "body": 3}[action]; wantArgs == 0 || fs.NArg() != wantArgs {
+		fs.Usage()
+		return ExitUsage
+	}
+	switch action {
+	case "list":
+		cassettes, err := cassette.List(*dir)
+		if err != nil {
+			fmt.Fprintln(s.stderr, err)
+			return ExitFailure
+		}
+		for _, c := range cassettes {
+			fmt.Fprintf(s.stdout, "%-14s %-9s %d %s\n", c.Provider, c.Cep, c.Status, c.RecordedAt.Format(time.RFC3339))
+		}
+	case "body":
+		c, err := cassette.Load(*dir, fs.Arg(1), fs.Arg(2))
+		if err != nil {
+			fmt.Fprintln(s.stderr, err)
+			return ExitFailure
+		}
+		body, err := c.BodyBytes()
+		if err != nil {
+			fmt.Fprintln(s.stderr, err)
+			return ExitFailure
+		}
+		s.stdout.Write(body)
+	}
+	return ExitOk
+}
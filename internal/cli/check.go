@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// runCheck runs "check [flags] CEP", which resolves the cep by racing the providers
+// and compares the -street, -neighborhood, -city and -state typed by a user with it.
+// It writes the usecase.AddressCheck as JSON, with the verdict on each field and the
+// suggested address, and logs to the standard error.
+// It returns ExitOk if no field is a mismatch, ExitFailure if one is, or the exit
+// status of the lookup error, such as ExitNotFound.
+func runCheck(args []string, s *streams) int {
+	fs := newFlagSet("check", "CEP", "Resolves the cep and checks the address typed by a user against it, field by field.", s)
+	street := fs.String("street", "", "street, with or without its type, such as \"Av. Herlindo Silveira\"")
+	neighborhood := fs.String("neighborhood", "", "neighborhood")
+	city := fs.String("city", "", "city")
+	state := fs.String("state", "", "UF or state name")
+	rf := addRaceFlags(fs)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	sess, err := rf.newSession(s.stderr)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	sigCtx, finish, interrupted := notifySession(sess)
+	defer finish()
+	ctx, cancel := context.WithTimeout(sigCtx, sess.cfg.Timeout.Duration)
+	defer cancel()
+
+	addr := usecase.Address{Cep: fs.Arg(0), Street: *street, Neighborhood: *neighborhood, City: *city, State: *state}
+	check, err := usecase.CheckAddress(ctx, addr, sess.opts)
+	if interrupted() {
+		return ExitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	if err := json.NewEncoder(s.stdout).Encode(check); err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	if !check.Valid {
+		return ExitFailure
+	}
+	return ExitOk
+}
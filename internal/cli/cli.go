@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// Program is the name of the command in the usage messages.
+const Program = "cep"
+
+// Exit statuses shared by all the commands.
+const (
+	// ExitOk means the command succeeded.
+	ExitOk = 0
+	// ExitFailure means the command failed for a reason without its own status,
+	// such as an unreadable file or an invalid config in "config validate".
+	ExitFailure = 1
+	// ExitUsage means the command line or the config is invalid: an unknown command
+	// or flag, a missing argument or an invalid setting.
+	ExitUsage = 2
+	// ExitInvalidCep means the cep was rejected before the race: it does not have
+	// 8 digits or is outside the state ranges.
+	ExitInvalidCep = 3
+	// ExitNotFound means every provider answered that the cep does not exist.
+	ExitNotFound = 4
+	// ExitAllProvidersFailed means every provider failed, not all with "not found".
+	ExitAllProvidersFailed = 5
+	// ExitTimeout means no provider answered within the timeout.
+	ExitTimeout = 6
+	// ExitInterrupted means the command was interrupted by SIGINT, SIGTERM or SIGHUP,
+	// following the shell convention of 128 plus the number of SIGINT.
+	ExitInterrupted = 130
+)
+
+// usageError is an error in the command line or the config, which exits with ExitUsage.
+type usageError struct {
+	err error
+}
+
+// Error returns the underlying error message.
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// exitStatus returns the exit status for the error of a command, as documented
+// in the Exit constants.
+func exitStatus(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return ExitOk
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, usecase.ErrInvalidCep):
+		return ExitInvalidCep
+	case errors.Is(err, dto.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, usecase.ErrAllProvidersFailed):
+		return ExitAllProvidersFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	default:
+		return ExitFailure
+	}
+}
+
+// streams are the standard streams of a command, replaced by buffers in the tests.
+type streams struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a subcommand: its name, a one-line summary for the command list,
+// and the function that runs it with the arguments after its name and returns
+// the exit status.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, s *streams) int
+}
+
+// commands returns the subcommands in the order they are listed by help.
+// It is a function, not a variable, because help lists the commands itself.
+func commands() []command {
+	return []command{
+		{"lookup", "resolve a cep by racing the providers", runLookup},
+		{"check", "check an address typed by a user against its cep", runCheck},
+		{"batch", "resolve the ceps of a file, one per line, as JSON lines", runBatch},
+		{"serve", "serve the lookups over HTTP", runServe},
+		{"repl", "resolve the ceps typed at a prompt, interactively", runRepl},
+		{"search", "search the local dataset and cache by state, city, neighborhood or street", runSearch},
+		{"cache", "inspect and maintain the cache of resolved ceps", runCache},
+		{"cassette", "inspect the provider responses recorded with -record", runCassette},
+		{"providers", "list the providers", runProviders},
+		{"config", "validate the config file", runConfig},
+		{"version", "print the version", runVersion},
+		{"help", "show the help of a command", runHelp},
+	}
+}
+
+// Run runs the command line args, without the program name, and returns the exit status.
+// The first argument names the subcommand. For compatibility with the single-flag
+// version of the CLI, arguments starting with a flag, as in "-cep 39408078", run lookup.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	s := &streams{stdin: stdin, stdout: stdout, stderr: stderr}
+	if len(args) == 0 {
+		usage(stderr)
+		return ExitUsage
+	}
+	if strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+		return runLookup(args, s)
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		if args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+			fmt.Fprintf(stderr, "%s: unknown command %q\n\n", Program, args[0])
+			usage(stderr)
+			return ExitUsage
+		}
+		usage(stdout)
+		return ExitOk
+	}
+	return cmd.run(args[1:], s)
+}
+
+// findCommand returns the subcommand with the given name.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usage prints the list of commands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: %s <command> [flags] [arguments]\n\ncommands:\n", Program)
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"%s help <command>\" for the flags of a command.\n", Program)
+	fmt.Fprintf(w, "\"%s -cep 39408078\" is the same as \"%s lookup -cep 39408078\".\n", Program, Program)
+}
+
+// runHelp prints the help of the named command, or the list of commands.
+func runHelp(args []string, s *streams) int {
+	if len(args) == 0 {
+		usage(s.stdout)
+		return ExitOk
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok || cmd.name == "help" {
+		fmt.Fprintf(s.stderr, "%s help: unknown command %q\n", Program, args[0])
+		return ExitUsage
+	}
+	return cmd.run([]string{"-h"}, &streams{stdin: s.stdin, stdout: s.stdout, stderr: s.stdout})
+}
+
+// newFlagSet creates the flag set of a command, printing its usage line, help text
+// and flags to the error stream.
+func newFlagSet(name, arguments, help string, s *streams) *flag.FlagSet {
+	fs := flag.NewFlagSet(Program+" "+name, flag.ContinueOnError)
+	fs.SetOutput(s.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s [flags] %s\n\n%s\n\nflags:\n", Program, name, arguments, help)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the arguments of a command. It returns false, and the exit
+// status, if the command must stop: ExitOk for -h, ExitUsage for an invalid flag.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOk, false
+	}
+	if err != nil {
+		return ExitUsage, false
+	}
+	return ExitOk, true
+}
+
+// isFlagSet reports whether the named flag was given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,399 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+const datasetCsv = "cep,uf,cidade,bairro,logradouro\n" +
+	"39408078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n" +
+	"01001000,SP,São Paulo,Sé,Praça da Sé\n"
+
+// writeFile writes data to a file in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// run runs the command line with the given standard input and returns the exit
+// status and the standard output.
+func run(t *testing.T, stdin string, args ...string) (int, string) {
+	t.Helper()
+	t.Setenv("CEP_CONFIG", "")
+	var stdout, stderr bytes.Buffer
+	status := Run(args, strings.NewReader(stdin), &stdout, &stderr)
+	t.Log(stderr.String())
+	return status, stdout.String()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantOut    string
+	}{
+		{name: "run without arguments", args: nil, wantStatus: ExitUsage},
+		{name: "run unknown command", args: []string{"find"}, wantStatus: ExitUsage},
+		{name: "run help", args: []string{"help"}, wantStatus: ExitOk, wantOut: "commands:"},
+		{name: "run help of a command", args: []string{"help", "serve"}, wantStatus: ExitOk, wantOut: "-addr"},
+		{name: "run help of the config command", args: []string{"help", "config"}, wantStatus: ExitOk, wantOut: "-file"},
+		{name: "run providers", args: []string{"providers"}, wantStatus: ExitOk, wantOut: "viacep (default)"},
+		{name: "run version", args: []string{"version"}, wantStatus: ExitOk, wantOut: Program + " " + Version},
+		{name: "run lookup without cep", args: []string{"lookup"}, wantStatus: ExitUsage},
+		{name: "run lookup with unknown flag", args: []string{"lookup", "-cpe", "39408078"}, wantStatus: ExitUsage},
+		{name: "run legacy lookup with invalid config", args: []string{"-cep", "39408078", "-providers", "correios"}, wantStatus: ExitUsage},
+		{name: "run lookup with invalid priorities", args: []string{"lookup", "-priorities", "viacep=high", "39408078"}, wantStatus: ExitUsage},
+		{name: "run config validate", args: []string{"config", "validate", "-file", "../../config.example.json"}, wantStatus: ExitOk, wantOut: "ok"},
+		{name: "run config without action", args: []string{"config"}, wantStatus: ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, out := run(t, "", tt.args...)
+			if status != tt.wantStatus {
+				t.Errorf("Run() = %d, want %d", status, tt.wantStatus)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("Run() output = %q, want it to contain %q", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRunConfigValidateInvalid(t *testing.T) {
+	path := writeFile(t, "cep.json", "{\n  \"output\": \"xml\"\n}\n")
+	t.Setenv("CEP_CONFIG", "")
+	var stdout, stderr bytes.Buffer
+	if status := Run([]string{"config", "validate", "-file", path}, nil, &stdout, &stderr); status != ExitFailure {
+		t.Errorf("Run() = %d, want %d", status, ExitFailure)
+	}
+	if want := path + ":2: output: output must be json or text"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("Run() errors = %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestRunLookupDataset(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	status, out := run(t, "", "-cep", "39408078", "-dataset", path, "-providers", "dataset")
+	if status != ExitOk || !strings.Contains(out, "Return from Dataset") {
+		t.Errorf("Run() = %d, %q, want the dataset to win", status, out)
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantOut    string
+	}{
+		{
+			name:       "check valid address",
+			args:       []string{"-street", "Pça. da Se", "-neighborhood", "Se", "-city", "Sao Paulo", "-state", "SP", "01001-000"},
+			wantStatus: ExitOk,
+			wantOut:    `"valid":true`,
+		},
+		{
+			name:       "check wrong city",
+			args:       []string{"-street", "Praça da Sé", "-city", "Santos", "-state", "SP", "01001000"},
+			wantStatus: ExitFailure,
+			wantOut:    `"field":"city","input":"Santos","expected":"São Paulo","verdict":"mismatch"`,
+		},
+		{
+			name:       "check unknown cep",
+			args:       []string{"-city", "Montes Claros", "39400000"},
+			wantStatus: ExitNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"check", "-dataset", path, "-providers", "dataset"}, tt.args...)
+			status, out := run(t, "", args...)
+			if status != tt.wantStatus || !strings.Contains(out, tt.wantOut) {
+				t.Errorf("Run() = %d, %q, want %d, %q", status, out, tt.wantStatus, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestRunLookupExitStatus(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	tests := []struct {
+		name string
+		cep  string
+		want int
+	}{
+		{name: "lookup found", cep: "39408078", want: ExitOk},
+		{name: "lookup not found", cep: "39400000", want: ExitNotFound},
+		{name: "lookup invalid cep", cep: "00000000", want: ExitInvalidCep},
+		{name: "lookup malformed cep", cep: "3940", want: ExitInvalidCep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := run(t, "", "lookup", "-dataset", path, "-providers", "dataset", tt.cep); status != tt.want {
+				t.Errorf("Run() = %d, want %d", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "exit success", err: nil, want: ExitOk},
+		{name: "exit usage", err: &usageError{errors.New("unknown provider")}, want: ExitUsage},
+		{name: "exit invalid cep", err: fmt.Errorf("%w: cep must have 8 digits", usecase.ErrInvalidCep), want: ExitInvalidCep},
+		{name: "exit not found", err: usecase.ErrCepNotFound, want: ExitNotFound},
+		{name: "exit all providers failed", err: usecase.ErrAllProvidersFailed, want: ExitAllProvidersFailed},
+		{name: "exit timeout", err: context.DeadlineExceeded, want: ExitTimeout},
+		{name: "exit interrupted", err: context.Canceled, want: ExitInterrupted},
+		{name: "exit failure", err: errors.New("permission denied"), want: ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitStatus(tt.err); got != tt.want {
+				t.Errorf("exitStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	status, out := run(t, "39408078\n# comment\n\n01001-000\n00000000\n", "batch", "-dataset", path, "-providers", "dataset")
+	if status != ExitInvalidCep {
+		t.Errorf("Run() = %d, want %d for the invalid cep", status, ExitInvalidCep)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Run() wrote %d lines, want 3: %q", len(lines), out)
+	}
+	want := []struct {
+		input string
+		city  string
+	}{{"39408078", "Montes Claros"}, {"01001-000", "São Paulo"}, {"00000000", ""}}
+	for i, line := range lines {
+		var got batchLine
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if got.Input != want[i].input {
+			t.Errorf("line %d input = %q, want %q", i, got.Input, want[i].input)
+		}
+		if want[i].city == "" {
+			if got.Error == nil || got.Error.Code != dto.CodeInvalidCep {
+				t.Errorf("line %d error = %+v, want code %s", i, got.Error, dto.CodeInvalidCep)
+			}
+			continue
+		}
+		if got.Result == nil || got.Result.Cep.City != want[i].city || got.Result.Provider != usecase.DatasetProviderName {
+			t.Errorf("line %d result = %+v, want %s from the dataset", i, got.Result, want[i].city)
+		}
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	c, err := cache.Open(cachePath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put(dto.Cep{Cep: "01310-100", State: "SP", City: "São Paulo", Neighborhood: "Bela Vista", Street: "Avenida Paulista"})
+	c.Put(dto.Cep{Cep: "39400-001", State: "MG", City: "Montes Claros", Neighborhood: "Centro", Street: "Av. Dr. Silva"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "search city without accents", args: []string{"-city", "sao paulo"}, want: []string{"01001000", "01310-100"}},
+		{name: "search state and street", args: []string{"-state", "mg", "-street", "HERLINDO"}, want: []string{"39408078"}},
+		{name: "search abbreviation", args: []string{"-street", "Dr"}, want: []string{"39400-001"}},
+		{name: "search abbreviation written in full", args: []string{"-street", "avenida doutor"}, want: []string{"39400-001"}},
+		{name: "search with limit", args: []string{"-state", "SP", "-limit", "1"}, want: []string{"01001000"}},
+		{name: "search without match", args: []string{"-city", "Recife"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"search", "-dataset", path, "-cache", cachePath}, tt.args...)
+			status, out := run(t, "", args...)
+			if status != ExitOk {
+				t.Fatalf("Run() = %d, want %d", status, ExitOk)
+			}
+			var got []string
+			dec := json.NewDecoder(strings.NewReader(out))
+			for dec.More() {
+				var c dto.Cep
+				if err := dec.Decode(&c); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, c.Cep)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	c, err := cache.Open(cachePath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put(dto.Cep{Cep: "39408078", State: "MG", City: "Montes Claros", Neighborhood: "Ibituruna", Street: "Avenida Herlindo Silveira"})
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, out := run(t, "", "cache", "-path", cachePath, "stats"); status != ExitOk || !strings.Contains(out, "entries: 1") {
+		t.Errorf("cache stats = %d, %q", status, out)
+	}
+	if status, out := run(t, "", "cache", "-path", cachePath, "get", "39408-078"); status != ExitOk || !strings.Contains(out, "Montes Claros") {
+		t.Errorf("cache get = %d, %q", status, out)
+	}
+	if status, _ := run(t, "", "cache", "-path", cachePath, "clear"); status != ExitOk {
+		t.Errorf("cache clear = %d", status)
+	}
+	if status, _ := run(t, "", "cache", "-path", cachePath, "get", "39408078"); status != ExitFailure {
+		t.Errorf("cache get after clear = %d, want %d", status, ExitFailure)
+	}
+	if status, _ := run(t, "", "cache", "-path", cachePath, "drop"); status != ExitUsage {
+		t.Errorf("cache drop = %d, want %d", status, ExitUsage)
+	}
+}
+
+func TestServeHandler(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	t.Setenv("CEP_CONFIG", "")
+	fs := newFlagSet("serve", "", "", &streams{stderr: &bytes.Buffer{}})
+	rf := addRaceFlags(fs)
+	if err := fs.Parse([]string{"-dataset", path, "-providers", "dataset"}); err != nil {
+		t.Fatal(err)
+	}
+	sess, err := rf.newSession(&bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(newHandler(sess))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "serve cep", path: "/cep/39408078", wantStatus: http.StatusOK, wantBody: `"city":"Montes Claros"`},
+		{name: "serve cep not found", path: "/cep/39400000", wantStatus: http.StatusNotFound, wantBody: `"code":"not_found"`},
+		{name: "serve invalid cep", path: "/cep/123", wantStatus: http.StatusBadRequest, wantBody: `"code":"invalid_cep"`},
+		{name: "serve check", path: "/cep/39408078/check?street=Av.+Herlindo+Silveira&city=Montes+Claros&state=MG", wantStatus: http.StatusOK, wantBody: `"valid":false`},
+		{name: "serve check not found", path: "/cep/39400000/check?city=Montes+Claros", wantStatus: http.StatusNotFound, wantBody: `"code":"not_found"`},
+		{name: "serve suggest", path: "/cep/suggest?prefix=394", wantStatus: http.StatusOK, wantBody: `{"ceps":[{"cep":"39408078"`},
+		{name: "serve suggest limit", path: "/cep/suggest?prefix=&limit=1", wantStatus: http.StatusOK, wantBody: `{"ceps":[{"cep":"01001000"`},
+		{name: "serve suggest no match", path: "/cep/suggest?prefix=9", wantStatus: http.StatusOK, wantBody: `{"ceps":[]}`},
+		{name: "serve suggest invalid prefix", path: "/cep/suggest?prefix=39a", wantStatus: http.StatusBadRequest, wantBody: `"code":"invalid_cep"`},
+		{name: "serve providers", path: "/providers", wantStatus: http.StatusOK, wantBody: `["dataset"]`},
+		{name: "serve health", path: "/healthz", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			var body bytes.Buffer
+			body.ReadFrom(res.Body)
+			if res.StatusCode != tt.wantStatus || !strings.Contains(body.String(), tt.wantBody) {
+				t.Errorf("GET %s = %d %q, want %d %q", tt.path, res.StatusCode, body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRunRepl(t *testing.T) {
+	path := writeFile(t, "dne.csv", datasetCsv)
+	stdin := "39408078\n:timeout 2s\n:timeout soon\n:providers nope\n:providers dataset\n01001000\n:history\n:foo\n:quit\n39408078\n"
+	status, out := run(t, stdin, "repl", "-dataset", path, "-providers", "dataset")
+	if status != ExitOk {
+		t.Fatalf("Run() = %d, want %d", status, ExitOk)
+	}
+	for _, want := range []string{
+		"cep> Avenida Herlindo Silveira, Ibituruna, Montes Claros - MG, 39408-078 (dataset, ",
+		"cep> 2s\n",
+		"cep> error: the timeout must be a positive duration",
+		"cep> error: unknown provider nope",
+		"cep> dataset\n",
+		"Praça da Sé, Sé, São Paulo - SP, 01001-000 (dataset, ",
+		"  1  39408078   Avenida Herlindo Silveira",
+		"  2  01001000   Praça da Sé",
+		"error: unknown command :foo",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Run() output %q, want it to contain %q", out, want)
+		}
+	}
+	if strings.Count(out, "39408-078") != 2 {
+		t.Errorf("Run() output %q, want the input after :quit ignored", out)
+	}
+}
+
+func TestRunRecordReplay(t *testing.T) {
+	body, err := os.ReadFile("../../responses/viacep.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	}))
+	configPath := writeFile(t, "cep.json", `{"timeout": "5s", "endpoints": {"viacep": {"base_url": "`+server.URL+`/ws/{{cep}}/json/"}}}`)
+	dir := filepath.Join(t.TempDir(), "cassettes")
+
+	lookup := func(mode string) (int, string) {
+		return run(t, "", "lookup", "-config", configPath, "-providers", "viacep", mode, dir, "39408078")
+	}
+	if status, out := lookup("-record"); status != ExitOk || !strings.Contains(out, "Return from Viacep") {
+		t.Fatalf("lookup -record = %d, %q", status, out)
+	}
+	server.Close()
+	if status, out := lookup("-replay"); status != ExitOk || !strings.Contains(out, "Return from Viacep") {
+		t.Errorf("lookup -replay = %d, %q", status, out)
+	}
+	if status, out := run(t, "", "cassette", "-dir", dir, "list"); status != ExitOk || !strings.HasPrefix(out, "viacep         39408078  200 ") {
+		t.Errorf("cassette list = %d, %q", status, out)
+	}
+	if status, out := run(t, "", "cassette", "-dir", dir, "body", "viacep", "39408078"); status != ExitOk || out != string(body) {
+		t.Errorf("cassette body = %d, %q, want the recorded body", status, out)
+	}
+	if status, _ := run(t, "", "lookup", "-record", dir, "-replay", dir, "39408078"); status != ExitUsage {
+		t.Errorf("lookup -record -replay = %d, want %d", status, ExitUsage)
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+)
+
+// runConfig runs "config validate [-file path]", which loads the config file, given
+// by -file or $CEP_CONFIG, applies the environment variables and prints every
+// invalid setting with its line. It returns ExitFailure if the config is invalid.
+func runConfig(args []string, s *streams) int {
+	fs := newFlagSet("config validate", "", "Validates the config file, listing the invalid settings with their lines.", s)
+	path := fs.String("file", "", "JSON config file (default $CEP_CONFIG)")
+	if len(args) == 0 || args[0] != "validate" {
+		fs.Usage()
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "-help") {
+			return ExitOk
+		}
+		return ExitUsage
+	}
+	if status, ok := parseFlags(fs, args[1:]); !ok {
+		return status
+	}
+	cfg, err := loadConfig(*path)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	if err := validate(cfg); err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	fmt.Fprintln(s.stdout, cfg.Path()+": ok")
+	return ExitOk
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// runLookup runs "lookup [flags] CEP", which races the providers for one cep and
+// logs the winner, as the CLI always did. The cep may also be given by -cep.
+// A SIGINT, SIGTERM or SIGHUP cancels the queries, which are drained for up to -drain
+// before the command returns ExitInterrupted; a second signal force-quits.
+// Otherwise it returns the exit status of the lookup error, such as ExitNotFound or ExitTimeout.
+func runLookup(args []string, s *streams) int {
+	fs := newFlagSet("lookup", "CEP", "Resolves the cep by racing the providers and logs the first valid response, or the best one within -grace.", s)
+	cep := fs.String("cep", "", "CEP, instead of the argument")
+	rf := addRaceFlags(fs)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if *cep == "" && fs.NArg() == 1 {
+		*cep = fs.Arg(0)
+	}
+	if *cep == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	sess, err := rf.newSession(s.stdout)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	sigCtx, finish, interrupted := notifySession(sess)
+	defer finish()
+	ctx, cancel := context.WithTimeout(sigCtx, sess.cfg.Timeout.Duration)
+	defer cancel()
+
+	err = usecase.ExecuteQueries(ctx, cancel, cep, sess.opts)
+	if interrupted() {
+		return ExitInterrupted
+	}
+	return exitStatus(err)
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+	"golang.org/x/exp/slices"
+)
+
+// runProviders runs "providers", which lists the built-in providers, one per line,
+// marking the ones raced by default, followed by the local providers and what
+// enables them.
+func runProviders(args []string, s *streams) int {
+	fs := newFlagSet("providers", "", "Lists the providers that -providers accepts.", s)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	for _, name := range usecase.Providers() {
+		if slices.Contains(usecase.DefaultProviders, name) {
+			fmt.Fprintln(s.stdout, name+" (default)")
+			continue
+		}
+		fmt.Fprintln(s.stdout, name)
+	}
+	fmt.Fprintln(s.stdout, usecase.DatasetProviderName+" (with -dataset)")
+	fmt.Fprintln(s.stdout, usecase.CacheProviderName+" (with the cache enabled)")
+	return ExitOk
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// replPrompt is printed before reading each line.
+const replPrompt = "cep> "
+
+// replHelp lists the commands of the REPL.
+const replHelp = `enter a cep to resolve it, or a command:
+  :providers [LIST]  show the providers raced, or race the comma-separated LIST
+  :timeout [D]       show the timeout, or set it to the duration D, such as 2s
+  :history           list the ceps resolved in this session
+  :help              show this help
+  :quit              leave, as does the end of the input
+`
+
+// replEntry is a cep resolved in the REPL, with the line printed for it.
+type replEntry struct {
+	input string
+	line  string
+}
+
+// repl is the state of an interactive session: the session it races with and
+// the ceps resolved so far.
+type repl struct {
+	sess    *session
+	out     io.Writer
+	history []replEntry
+}
+
+// runRepl runs "repl [flags]", which reads ceps and commands from the standard input,
+// one per line after a prompt, and prints the address of each cep. The providers,
+// their connections and the cache are kept between lookups, and the cache is saved
+// on leaving. The logs go to the standard error.
+// It returns ExitOk on :quit or at the end of the input, and ExitInterrupted on a
+// signal, which also cancels the lookup running.
+func runRepl(args []string, s *streams) int {
+	fs := newFlagSet("repl", "", "Resolves the ceps typed at the prompt, one per line; type :help for the commands.", s)
+	rf := addRaceFlags(fs)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	sess, err := rf.newSession(s.stderr)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	ctx, finish, interrupted := notifySession(sess)
+	defer finish()
+	r := &repl{sess: sess, out: s.stdout}
+	err = r.run(ctx, s.stdin)
+	if interrupted() {
+		fmt.Fprintln(s.stdout)
+		return ExitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	return ExitOk
+}
+
+// run reads the lines of in until :quit, the end of in or ctx is done, which may
+// happen while in is blocked in a read, and evaluates each one.
+// It returns the error reading in.
+func (r *repl) run(ctx context.Context, in io.Reader) error {
+	lines := make(chan string)
+	var readErr error
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+	for {
+		fmt.Fprint(r.out, replPrompt)
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				fmt.Fprintln(r.out)
+				return readErr
+			}
+			if !r.eval(ctx, strings.TrimSpace(line)) {
+				return nil
+			}
+		}
+	}
+}
+
+// eval evaluates a line: a command, if it starts with ':', or else a cep.
+// It returns false on :quit.
+func (r *repl) eval(ctx context.Context, line string) bool {
+	if line == "" {
+		return true
+	}
+	if !strings.HasPrefix(line, ":") {
+		r.lookup(ctx, line)
+		return true
+	}
+	name, arg, _ := strings.Cut(line[1:], " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "quit", "q", "exit":
+		return false
+	case "help", "h":
+		fmt.Fprint(r.out, replHelp)
+	case "providers":
+		if arg != "" {
+			names, err := usecase.ParseProviders(arg)
+			if err != nil {
+				fmt.Fprintln(r.out, "error: "+err.Error())
+				return true
+			}
+			r.sess.opts.Providers = names
+		}
+		fmt.Fprintln(r.out, strings.Join(r.sess.opts.Providers, ", "))
+	case "timeout":
+		if arg != "" {
+			timeout, err := time.ParseDuration(arg)
+			if err != nil || timeout <= 0 {
+				fmt.Fprintln(r.out, "error: the timeout must be a positive duration, such as 2s")
+				return true
+			}
+			r.sess.cfg.Timeout.Duration = timeout
+		}
+		fmt.Fprintln(r.out, r.sess.cfg.Timeout.Duration)
+	case "history":
+		for i, entry := range r.history {
+			fmt.Fprintf(r.out, "%3d  %-9s  %s\n", i+1, entry.input, entry.line)
+		}
+	default:
+		fmt.Fprintf(r.out, "error: unknown command :%s; type :help for the commands\n", name)
+	}
+	return true
+}
+
+// lookup resolves the cep within the timeout, prints its address or the error,
+// and adds it to the history.
+func (r *repl) lookup(ctx context.Context, cep string) {
+	ctx, cancel := context.WithTimeout(ctx, r.sess.cfg.Timeout.Duration)
+	defer cancel()
+	start := time.Now()
+	result, err := usecase.Lookup(ctx, cep, r.sess.opts)
+	var line string
+	if err != nil {
+		line = "error: " + err.Error()
+	} else {
+		line = fmt.Sprintf("%s (%s, %s)", result.Cep.Label(dto.LabelOptions{}).Line(), result.Provider, time.Since(start).Round(time.Millisecond))
+	}
+	fmt.Fprintln(r.out, line)
+	r.history = append(r.history, replEntry{input: cep, line: line})
+}
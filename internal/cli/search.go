@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+// searchQuery is what search looks for. Empty fields match anything; the others
+// match ignoring case, accents and abbreviations, the state exactly and the rest
+// as substrings.
+type searchQuery struct {
+	state        string
+	city         string
+	neighborhood string
+	street       string
+}
+
+// matches reports whether the cep matches every field of the query.
+func (q searchQuery) matches(c dto.Cep) bool {
+	return (q.state == "" || strings.EqualFold(c.State, q.state)) &&
+		containsNormalized(c.City, q.city) &&
+		containsNormalized(c.Neighborhood, q.neighborhood) &&
+		containsNormalized(c.Street, q.street)
+}
+
+// containsNormalized reports whether s contains substr once both are folded, so
+// "Dr" finds "Av. Dr. Silva", or once both are normalized by
+// shared.NormalizeAddress, so "Av. Dr." finds "Avenida Doutor".
+func containsNormalized(s, substr string) bool {
+	return substr == "" ||
+		strings.Contains(shared.Fold(s), shared.Fold(substr)) ||
+		strings.Contains(shared.NormalizeAddress(s), shared.NormalizeAddress(substr))
+}
+
+// runSearch runs "search [flags]", which lists, as JSON lines, the ceps of the local
+// dataset and of the cache that match the -state, -city, -neighborhood and -street
+// flags. The dataset and the cache come from the config or from -dataset and -cache.
+// It returns ExitFailure if there is nothing to search.
+func runSearch(args []string, s *streams) int {
+	fs := newFlagSet("search", "", "Lists the ceps of the local dataset and of the cache matching the flags, ignoring case and accents.", s)
+	configPath := fs.String("config", "", "JSON config file (default $CEP_CONFIG)")
+	datasetPath := fs.String("dataset", "", "CSV or fixed-width DNE export to search")
+	cachePath := fs.String("cache", "", "cache file to search; by default the cache of the config, when enabled")
+	var q searchQuery
+	fs.StringVar(&q.state, "state", "", "state (UF), such as MG")
+	fs.StringVar(&q.city, "city", "", "part of the city name")
+	fs.StringVar(&q.neighborhood, "neighborhood", "", "part of the neighborhood name")
+	fs.StringVar(&q.street, "street", "", "part of the street name")
+	limit := fs.Int("limit", 20, "maximum number of ceps listed; 0 lists all")
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() > 0 || *limit < 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	setLogger(s.stderr, cfg.Output)
+	if *datasetPath == "" {
+		*datasetPath = cfg.Dataset
+	}
+	if *cachePath == "" && cfg.Cache.Enabled {
+		*cachePath = cfg.Cache.Path
+	}
+	if *datasetPath == "" && *cachePath == "" {
+		fmt.Fprintln(s.stderr, "nothing to search: give a dataset or a cache")
+		return ExitFailure
+	}
+
+	found, err := search(*datasetPath, *cachePath, cfg.Cache.Ttl.Duration, q, *limit)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	if err := writeJsonLines(s.stdout, found); err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	return ExitOk
+}
+
+// search returns the ceps of the dataset and the cache at the given paths, which may
+// be empty, that match q, up to limit ceps. Cache entries older than ttl are skipped.
+// A cep found in both is listed once, from the dataset.
+func search(datasetPath, cachePath string, ttl time.Duration, q searchQuery, limit int) ([]dto.Cep, error) {
+	var found []dto.Cep
+	seen := map[string]bool{}
+	if datasetPath != "" {
+		ds, err := loadDataset(datasetPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range ds.Filter(q.matches, limit) {
+			seen[c.Cep] = true
+			found = append(found, c)
+		}
+	}
+	if cachePath != "" {
+		c, err := cache.Open(cachePath, ttl)
+		if err != nil {
+			return nil, err
+		}
+		for _, cep := range c.All() {
+			if limit > 0 && len(found) == limit {
+				break
+			}
+			if !seen[cep.Cep] && q.matches(cep) {
+				found = append(found, cep)
+			}
+		}
+	}
+	return found, nil
+}
+
+// writeJsonLines writes each cep as a JSON line.
+func writeJsonLines(w io.Writer, ceps []dto.Cep) error {
+	enc := json.NewEncoder(w)
+	var errs []error
+	for _, c := range ceps {
+		errs = append(errs, enc.Encode(c))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/suggest"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// runServe runs "serve [flags]", an HTTP server for the lookups:
+//
+//	GET /cep/{cep}        the winner of the race, as a usecase.Result
+//	GET /cep/{cep}/check  the usecase.AddressCheck of the street, neighborhood,
+//	                      city and state query parameters
+//	GET /cep/suggest      the ceps of the dataset and the cache starting with the
+//	                      prefix query parameter, up to limit, 10 by default
+//	GET /providers        the providers raced
+//	GET /healthz          200 while the server is up
+//
+// On SIGINT, SIGTERM or SIGHUP it stops accepting connections, waits up to -drain
+// for the requests in flight and returns ExitOk, the usual way to stop a server.
+// A second signal force-quits.
+func runServe(args []string, s *streams) int {
+	fs := newFlagSet("serve", "", "Serves the lookups over HTTP: GET /cep/{cep}, GET /cep/{cep}/check, GET /cep/suggest, GET /providers and GET /healthz.", s)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	rf := addRaceFlags(fs)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	sess, err := rf.newSession(s.stdout)
+	if err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return exitStatus(err)
+	}
+	ctx, finish, _ := notifySession(sess)
+	defer finish()
+	server := &http.Server{Addr: *addr, Handler: newHandler(sess), ReadHeaderTimeout: 5 * time.Second}
+	errs := make(chan error, 1)
+	go func() {
+		slog.Info("serve: listening", "addr", *addr)
+		errs <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	case <-ctx.Done():
+	}
+	slog.Info("serve: shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), sess.cfg.Drain.Duration)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintln(s.stderr, err)
+		return ExitFailure
+	}
+	return ExitOk
+}
+
+// Limits of the ceps answered by GET /cep/suggest.
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 100
+)
+
+// newHandler returns the routes of the server.
+func newHandler(sess *session) http.Handler {
+	mux := http.NewServeMux()
+	index := newSuggestIndex(sess)
+	mux.HandleFunc("GET /cep/suggest", func(w http.ResponseWriter, r *http.Request) {
+		prefix := r.URL.Query().Get("prefix")
+		if !suggest.ValidPrefix(prefix) {
+			err := fmt.Errorf("%w prefix %q: want up to 8 digits", usecase.ErrInvalidCep, prefix)
+			writeJson(w, http.StatusBadRequest, map[string]*dto.ErrorDetail{"error": dto.NewErrorDetail(err, "")})
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit < 1 {
+			limit = defaultSuggestLimit
+		}
+		ceps := index.Suggest(prefix, min(limit, maxSuggestLimit))
+		if ceps == nil {
+			ceps = []dto.Cep{}
+		}
+		writeJson(w, http.StatusOK, map[string][]dto.Cep{"ceps": ceps})
+	})
+	mux.HandleFunc("GET /cep/{cep}", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), sess.cfg.Timeout.Duration)
+		defer cancel()
+		result, err := usecase.Lookup(ctx, r.PathValue("cep"), sess.opts)
+		if err != nil {
+			writeJson(w, lookupStatus(err), map[string]*dto.ErrorDetail{"error": dto.NewErrorDetail(err, "")})
+			return
+		}
+		writeJson(w, http.StatusOK, result)
+	})
+	mux.HandleFunc("GET /cep/{cep}/check", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), sess.cfg.Timeout.Duration)
+		defer cancel()
+		query := r.URL.Query()
+		addr := usecase.Address{
+			Cep:          r.PathValue("cep"),
+			Street:       query.Get("street"),
+			Neighborhood: query.Get("neighborhood"),
+			City:         query.Get("city"),
+			State:        query.Get("state"),
+		}
+		check, err := usecase.CheckAddress(ctx, addr, sess.opts)
+		if err != nil {
+			writeJson(w, lookupStatus(err), map[string]*dto.ErrorDetail{"error": dto.NewErrorDetail(err, "")})
+			return
+		}
+		writeJson(w, http.StatusOK, check)
+	})
+	mux.HandleFunc("GET /providers", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, map[string][]string{"providers": sess.opts.Providers})
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// newSuggestIndex indexes the ceps of the dataset and of the cache of the session,
+// as they are when the server starts; the ceps cached later are not suggested.
+func newSuggestIndex(sess *session) *suggest.Index {
+	var sources [][]dto.Cep
+	if sess.dataset != nil {
+		sources = append(sources, sess.dataset.All())
+	}
+	if sess.cache != nil {
+		sources = append(sources, sess.cache.All())
+	}
+	index := suggest.New(sources...)
+	slog.Info("serve: suggest index built", "ceps", index.Len())
+	return index
+}
+
+// lookupStatus returns the HTTP status of a failed lookup: 400 for a cep rejected
+// before the race, such as one with less than 8 digits or outside the state ranges,
+// 404 when every provider answered "not found", 504 when the providers took too
+// long, 503 when the request was canceled and 502 when all of them failed.
+func lookupStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCep):
+		return http.StatusBadRequest
+	case errors.Is(err, dto.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// writeJson writes v as the JSON body of a response with the given status.
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Info("serve: " + err.Error())
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cassette"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/config"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+	"golang.org/x/exp/slices"
+)
+
+// raceFlags are the flags of the commands that race the providers. They override
+// the config file and the environment variables.
+type raceFlags struct {
+	fs         *flag.FlagSet
+	configPath *string
+	providers  *string
+	priorities *string
+	weights    *string
+	grace      *time.Duration
+	timeout    *time.Duration
+	drain      *time.Duration
+	dataset    *string
+	record     *string
+	replay     *string
+}
+
+// addRaceFlags defines the race flags in fs.
+func addRaceFlags(fs *flag.FlagSet) *raceFlags {
+	return &raceFlags{
+		fs:         fs,
+		configPath: fs.String("config", "", "JSON config file (default $CEP_CONFIG); see config.example.json"),
+		providers: fs.String("providers", strings.Join(usecase.DefaultProviders, ","),
+			"comma-separated providers to race: "+strings.Join(usecase.Providers(), ", ")),
+		priorities: fs.String("priorities", "", "comma-separated provider=priority pairs; higher priorities are preferred, e.g. dataset=10,brasilapi-v2=5"),
+		weights:    fs.String("weights", "", "comma-separated provider=weight pairs, from 0 to 1, ranking providers of equal priority"),
+		grace:      fs.Duration("grace", 0, "how long to wait, after the first valid response, for a higher ranked provider"),
+		timeout:    fs.Duration("timeout", time.Second, "how long to wait for the providers"),
+		drain:      fs.Duration("drain", 2*time.Second, "how long to wait, on exit or on a signal, for the work still running"),
+		dataset:    fs.String("dataset", "", "CSV or fixed-width DNE export to load and race as the \"dataset\" provider"),
+		record:     fs.String("record", "", "folder to record the provider responses to, as cassettes by provider and cep"),
+		replay:     fs.String("replay", "", "folder of cassettes to answer the HTTP providers from, instead of the network"),
+	}
+}
+
+// session is what a command needs to race the providers: the validated config, the
+// race options, and the dataset and the cache, if enabled.
+type session struct {
+	cfg     config.Config
+	opts    usecase.RaceOptions
+	dataset *dataset.Dataset
+	cache   *cache.Cache
+}
+
+// newSession builds the config from the config file, the environment variables and
+// the flags, each overriding the previous one, validates it, sends the logs to logTo
+// in the configured format, and sets up the providers: the dataset and the cache are
+// loaded and registered, and the endpoint, retry and circuit breaker settings applied.
+// It returns the validation errors joined, one per line, as a *usageError, like the
+// errors of the flags.
+func (f *raceFlags) newSession(logTo io.Writer) (*session, error) {
+	cfg, err := loadConfig(*f.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if isFlagSet(f.fs, "providers") {
+		cfg.SetProviders(*f.providers)
+	}
+	if isFlagSet(f.fs, "grace") {
+		cfg.Grace.Duration = *f.grace
+	}
+	if isFlagSet(f.fs, "timeout") {
+		cfg.Timeout.Duration = *f.timeout
+	}
+	if isFlagSet(f.fs, "drain") {
+		cfg.Drain.Duration = *f.drain
+	}
+	if isFlagSet(f.fs, "dataset") {
+		cfg.Dataset = *f.dataset
+	}
+	if !isFlagSet(f.fs, "providers") {
+		cfg.Providers = withLocalProviders(cfg)
+	}
+	if err := validate(cfg); err != nil {
+		return nil, err
+	}
+	if *f.record != "" && *f.replay != "" {
+		return nil, &usageError{errors.New("-record and -replay cannot be used together")}
+	}
+	setLogger(logTo, cfg.Output)
+
+	ds, c, err := setupProviders(cfg, f.cassettes())
+	if err != nil {
+		return nil, err
+	}
+	priorities, err := usecase.ParsePriorities(*f.priorities)
+	if err != nil {
+		return nil, &usageError{err}
+	}
+	weights, err := usecase.ParseWeights(*f.weights)
+	if err != nil {
+		return nil, &usageError{err}
+	}
+	return &session{
+		cfg: cfg,
+		opts: usecase.RaceOptions{
+			Providers:   cfg.Providers,
+			GraceWindow: cfg.Grace.Duration,
+			Priorities:  merge(cfg.Priorities, priorities),
+			Weights:     merge(cfg.Weights, weights),
+		},
+		dataset: ds,
+		cache:   c,
+	}, nil
+}
+
+// close waits up to the drain of the config for the queries still running, so they
+// log their cancellation, and saves the cache, if enabled, logging a failure.
+func (s *session) close() {
+	if !usecase.Drain(s.cfg.Drain.Duration) {
+		slog.Info("cli: queries still running after " + s.cfg.Drain.Duration.String())
+	}
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Save(); err != nil {
+		slog.Info("cli: " + err.Error())
+	}
+}
+
+// cassettes returns the cassette transport of -record or -replay, without Next,
+// or nil if neither is given.
+func (f *raceFlags) cassettes() *cassette.Transport {
+	switch {
+	case *f.record != "":
+		return &cassette.Transport{Dir: *f.record, Mode: cassette.Record, Key: usecase.RequestQuery}
+	case *f.replay != "":
+		return &cassette.Transport{Dir: *f.replay, Mode: cassette.Replay, Key: usecase.RequestQuery}
+	default:
+		return nil
+	}
+}
+
+// loadConfig returns the config file at path, or at $CEP_CONFIG if path is empty,
+// or the default config if neither is given, overridden by the environment variables.
+// Its errors are *usageError.
+func loadConfig(path string) (config.Config, error) {
+	if path == "" {
+		path = os.Getenv("CEP_CONFIG")
+	}
+	cfg := config.Default()
+	if path != "" {
+		var err error
+		if cfg, err = config.Load(path); err != nil {
+			return config.Config{}, &usageError{err}
+		}
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return config.Config{}, &usageError{err}
+	}
+	return cfg, nil
+}
+
+// validate returns the errors of cfg.Validate joined as a *usageError, or nil.
+func validate(cfg config.Config) error {
+	var errs []error
+	for _, err := range cfg.Validate() {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &usageError{errors.Join(errs...)}
+}
+
+// setLogger sends the logs to w, in JSON or, for the text output, in text.
+func setLogger(w io.Writer, output string) {
+	if output == config.OutputText {
+		slog.SetDefault(slog.New(slog.NewTextHandler(w, nil)))
+		return
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, nil)))
+}
+
+// withLocalProviders returns the providers of cfg with the "cache" and "dataset"
+// providers in front when they are configured and not listed yet, so they answer first.
+func withLocalProviders(cfg config.Config) []string {
+	var local []string
+	if cfg.Cache.Enabled && !slices.Contains(cfg.Providers, usecase.CacheProviderName) {
+		local = append(local, usecase.CacheProviderName)
+	}
+	if cfg.Dataset != "" && !slices.Contains(cfg.Providers, usecase.DatasetProviderName) {
+		local = append(local, usecase.DatasetProviderName)
+	}
+	return append(local, cfg.Providers...)
+}
+
+// setupProviders loads the dataset and opens the cache given in cfg, registering them
+// as providers, and applies the endpoint, retry and circuit breaker settings of cfg to
+// every provider, giving each one an HTTP client of its own, so a high volume of
+// lookups reuses the connections of each host. With tape, the clients record to or
+// replay from its cassettes. It returns the dataset and the cache, nil when disabled.
+func setupProviders(cfg config.Config, tape *cassette.Transport) (*dataset.Dataset, *cache.Cache, error) {
+	var ds *dataset.Dataset
+	if cfg.Dataset != "" {
+		var err error
+		if ds, err = loadDataset(cfg.Dataset); err != nil {
+			return nil, nil, err
+		}
+		usecase.RegisterDataset(ds)
+	}
+	var c *cache.Cache
+	if cfg.Cache.Enabled {
+		var err error
+		if c, err = cache.Open(cfg.Cache.Path, cfg.Cache.Ttl.Duration); err != nil {
+			return nil, nil, err
+		}
+		usecase.RegisterCache(c)
+	}
+	for _, name := range usecase.Providers() {
+		settings := cfg.ProviderSettings(name)
+		client, err := usecase.NewClient(cfg.ClientOptions())
+		if err != nil {
+			return nil, nil, &usageError{err}
+		}
+		if tape != nil {
+			client.Transport = &cassette.Transport{Dir: tape.Dir, Mode: tape.Mode, Key: tape.Key, Next: client.Transport}
+		}
+		settings.Client = client
+		usecase.ConfigureProvider(name, settings)
+	}
+	return ds, c, nil
+}
+
+// loadDataset loads the DNE export at path.
+// Invalid rows are skipped and counted in the log; use the dneloader command to list them.
+func loadDataset(path string) (*dataset.Dataset, error) {
+	ds, rowErrors, err := dataset.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("cli: dataset loaded", "path", path, "ceps", ds.Len(), "invalid_rows", len(rowErrors))
+	return ds, nil
+}
+
+// merge returns the entries of base overridden by the entries of override.
+func merge[V any](base, override map[string]V) map[string]V {
+	merged := make(map[string]V, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
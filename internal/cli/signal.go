@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// signals are the signals that interrupt a command.
+var signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// exit ends the process; the tests replace it.
+var exit = os.Exit
+
+// notifyContext returns a context, based on signal.NotifyContext, that is canceled
+// by the first SIGINT, SIGTERM or SIGHUP, so the command can cancel its work, drain
+// it and flush its output. A second signal force-quits with ExitInterrupted.
+// The stop function releases the signals and must be called when the command ends;
+// interrupted reports whether a signal canceled the context.
+func notifyContext(parent context.Context) (ctx context.Context, stop func(), interrupted func() bool) {
+	ctx, stopNotify := signal.NotifyContext(parent, signals...)
+	done := make(chan struct{})
+	var mu sync.Mutex
+	stopped, signaled := false, false
+	interrupted = func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			signaled = ctx.Err() != nil && parent.Err() == nil
+		}
+		return signaled
+	}
+	stop = func() {
+		interrupted()
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			stopped = true
+			close(done)
+			stopNotify()
+		}
+	}
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+		if !interrupted() {
+			return
+		}
+		force := make(chan os.Signal, 1)
+		signal.Notify(force, signals...)
+		defer signal.Stop(force)
+		slog.Info("cli: interrupted, finishing the work in flight; interrupt again to force quit")
+		select {
+		case <-done:
+		case <-force:
+			slog.Info("cli: forced quit")
+			exit(ExitInterrupted)
+		}
+	}()
+	return ctx, stop, interrupted
+}
+
+// notifySession is notifyContext for a command racing the providers. Its finish
+// function closes the session, draining the queries still running, before it
+// releases the signals, so a second signal during the drain still force-quits
+// with ExitInterrupted instead of killing the process.
+func notifySession(sess *session) (ctx context.Context, finish func(), interrupted func() bool) {
+	ctx, stop, interrupted := notifyContext(context.Background())
+	finish = func() {
+		sess.close()
+		stop()
+	}
+	return ctx, finish, interrupted
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/config"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+func TestNotifyContext(t *testing.T) {
+	t.Run("stopped without signal", func(t *testing.T) {
+		ctx, stop, interrupted := notifyContext(context.Background())
+		stop()
+		if ctx.Err() == nil {
+			t.Error("context not canceled by stop")
+		}
+		if interrupted() {
+			t.Error("interrupted() = true, want false")
+		}
+	})
+
+	t.Run("parent canceled", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		_, stop, interrupted := notifyContext(parent)
+		defer stop()
+		cancel()
+		if interrupted() {
+			t.Error("interrupted() = true, want false")
+		}
+	})
+
+	t.Run("signal, then force quit", func(t *testing.T) {
+		forced := make(chan int, 1)
+		saved := exit
+		exit = func(code int) { forced <- code }
+		defer func() { exit = saved }()
+
+		ctx, stop, interrupted := notifyContext(context.Background())
+		defer stop()
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("context not canceled by SIGINT")
+		}
+		if !interrupted() {
+			t.Error("interrupted() = false, want true")
+		}
+
+		if code := forceQuit(t, forced); code != ExitInterrupted {
+			t.Errorf("exit(%d), want exit(%d)", code, ExitInterrupted)
+		}
+	})
+
+	t.Run("signal, then force quit during the drain", testForceQuitDuringDrain)
+}
+
+// forceQuit sends SIGINT until the force quit handler calls exit, and returns its
+// status. The handler is registered right after the context is canceled.
+func forceQuit(t *testing.T, forced <-chan int) int {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case code := <-forced:
+			return code
+		case <-deadline:
+			t.Fatal("second SIGINT did not force quit")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// testForceQuitDuringDrain checks that a second signal while notifySession's finish
+// drains the queries still force-quits.
+func testForceQuitDuringDrain(t *testing.T) {
+	forced := make(chan int, 1)
+	saved := exit
+	exit = func(code int) { forced <- code }
+	defer func() { exit = saved }()
+
+	// The provider ignores the cancellation until released, so the drain waits for it.
+	release := make(chan struct{})
+	usecase.RegisterProvider("stuck", func(ctx context.Context, cancel context.CancelFunc, cep string) *usecase.CepQuery {
+		return &usecase.CepQuery{
+			Context: ctx, Cancel: cancel, Cep: cep, Channel: make(chan dto.Response, 1), ServiceName: "Stuck",
+			Lookup: func(c *usecase.CepQuery) (dto.Cep, error) {
+				<-release
+				return dto.Cep{}, dto.ErrNotFound
+			},
+		}
+	})
+	sess := &session{
+		cfg:  config.Config{Drain: config.Duration{Duration: 10 * time.Second}},
+		opts: usecase.RaceOptions{Providers: []string{"stuck"}},
+	}
+
+	ctx, finish, interrupted := notifySession(sess)
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := usecase.Lookup(ctx, "39408078", sess.opts)
+		lookup <- err
+	}()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	<-lookup
+	if !interrupted() {
+		t.Fatal("interrupted() = false, want true")
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		finish()
+		close(finished)
+	}()
+	if code := forceQuit(t, forced); code != ExitInterrupted {
+		t.Errorf("exit(%d), want exit(%d)", code, ExitInterrupted)
+	}
+	select {
+	case <-finished:
+		t.Error("finish() returned before the drain ended")
+	default:
+	}
+	close(release)
+	<-finished
+}
+
+func TestBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out strings.Builder
+	// The reader never ends, like a terminal nobody types in.
+	r, w := io.Pipe()
+	defer w.Close()
+	status, err := batch(ctx, &session{}, r, &out, 2)
+	if status != ExitOk || err != nil {
+		t.Errorf("batch() = %d, %v, want %d, nil", status, err, ExitOk)
+	}
+	if out.Len() != 0 {
+		t.Errorf("batch() wrote %q, want nothing", out.String())
+	}
+}
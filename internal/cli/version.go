@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the version of the program, set at build time with
+// -ldflags "-X github.com/antoniofmoliveira/fullcycle-multithreading/internal/cli.Version=v1.2.3".
+var Version = "dev"
+
+// runVersion runs "version", which prints the version, the vcs revision the program
+// was built from, when known, and the Go version.
+func runVersion(args []string, s *streams) int {
+	fs := newFlagSet("version", "", "Prints the version.", s)
+	if status, ok := parseFlags(fs, args); !ok {
+		return status
+	}
+	line := Program + " " + Version
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				line += " " + setting.Value
+			}
+		}
+	}
+	fmt.Fprintln(s.stdout, line+" "+runtime.Version())
+	return ExitOk
+}
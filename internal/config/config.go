@@ -0,0 +1,387 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+	"golang.org/x/exp/slices"
+)
+
+// Output formats of the logs and results.
+const (
+	OutputJson = "json"
+	OutputText = "text"
+)
+
+// Config holds the settings of the CLI and the server. It is read from a JSON
+// file by Load, overridden by environment variables by ApplyEnv and then by the
+// command-line flags, and checked by Validate before use.
+type Config struct {
+	// Providers are the providers to race, in order.
+	Providers []string `json:"providers"`
+	// Timeout bounds a whole lookup.
+	Timeout Duration `json:"timeout"`
+	// Drain bounds how long the commands wait, on exit or on a signal, for the
+	// queries and requests still running.
+	Drain Duration `json:"drain"`
+	// Grace is the grace window of the race; see usecase.RaceOptions.
+	Grace      Duration           `json:"grace"`
+	Priorities map[string]int     `json:"priorities"`
+	Weights    map[string]float64 `json:"weights"`
+	// Dataset is the path of a DNE export raced as the "dataset" provider.
+	Dataset string `json:"dataset"`
+	// Output is the format of the logs and results, "json" or "text".
+	Output string `json:"output"`
+	// Endpoints override the base url and timeout of providers, by name.
+	Endpoints      map[string]Endpoint `json:"endpoints"`
+	Retry          Retry               `json:"retry"`
+	CircuitBreaker CircuitBreaker      `json:"circuit_breaker"`
+	Cache          Cache               `json:"cache"`
+	Http           Http                `json:"http"`
+
+	// path is the file the config was loaded from, and lines the line of each field in it.
+	path  string
+	lines map[string]int
+}
+
+// Endpoint overrides the url and timeout of one provider.
+type Endpoint struct {
+	// BaseUrl is the url template of the provider, with "{{cep}}" where the cep goes.
+	BaseUrl string   `json:"base_url"`
+	Timeout Duration `json:"timeout"`
+}
+
+// Retry tells how many times a failing provider is tried.
+type Retry struct {
+	Attempts int      `json:"attempts"`
+	Backoff  Duration `json:"backoff"`
+}
+
+// CircuitBreaker tells when a failing provider is left out of the races.
+type CircuitBreaker struct {
+	Failures int      `json:"failures"`
+	Cooldown Duration `json:"cooldown"`
+}
+
+// Cache configures the cache of resolved ceps.
+type Cache struct {
+	Enabled bool     `json:"enabled"`
+	Path    string   `json:"path"`
+	Ttl     Duration `json:"ttl"`
+}
+
+// Http tunes the HTTP clients of the providers; zero values keep the defaults of
+// usecase.ClientOptions.
+type Http struct {
+	UserAgent string `json:"user_agent"`
+	// Proxy is the url of the proxy; empty uses the HTTP_PROXY and HTTPS_PROXY variables.
+	Proxy                 string   `json:"proxy"`
+	MaxIdleConnsPerHost   int      `json:"max_idle_conns_per_host"`
+	IdleConnTimeout       Duration `json:"idle_conn_timeout"`
+	TLSHandshakeTimeout   Duration `json:"tls_handshake_timeout"`
+	ResponseHeaderTimeout Duration `json:"response_header_timeout"`
+}
+
+// Default returns the config used when no file is given: the default providers,
+// a 1 second timeout, a 2 seconds drain, a single try per provider, JSON output
+// and the cache disabled.
+func Default() Config {
+	return Config{
+		Providers: append([]string(nil), usecase.DefaultProviders...),
+		Timeout:   Duration{Duration: time.Second},
+		Drain:     Duration{Duration: 2 * time.Second},
+		Output:    OutputJson,
+		Retry:     Retry{Attempts: 1},
+		Cache:     Cache{Path: "cep-cache.json", Ttl: Duration{Duration: 24 * time.Hour}},
+	}
+}
+
+// Load reads the JSON config file at path over the Default config, so the file
+// only needs the settings it changes. Unknown fields are rejected.
+// A syntax error, an unknown field or a value of the wrong type is returned as a
+// *FieldError with its line number. Invalid values are reported by Validate.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return Parse(path, data)
+}
+
+// Parse is Load for a config already read; path is used in the error messages.
+func Parse(path string, data []byte) (Config, error) {
+	c := Default()
+	c.path = path
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, decodeError(path, data, err)
+	}
+	c.lines = indexLines(data)
+	return c, nil
+}
+
+// Path returns the file the config was loaded from, or "" for the Default config.
+func (c Config) Path() string {
+	return c.path
+}
+
+// envVars lists the environment variables read by ApplyEnv and the field each one sets.
+var envVars = []struct {
+	name  string
+	field string
+	set   func(c *Config, value string) error
+}{
+	{"CEP_PROVIDERS", "providers", func(c *Config, v string) error {
+		c.SetProviders(v)
+		return nil
+	}},
+	{"CEP_TIMEOUT", "timeout", func(c *Config, v string) error { return c.Timeout.Set(v) }},
+	{"CEP_GRACE", "grace", func(c *Config, v string) error { return c.Grace.Set(v) }},
+	{"CEP_DRAIN", "drain", func(c *Config, v string) error { return c.Drain.Set(v) }},
+	{"CEP_DATASET", "dataset", func(c *Config, v string) error {
+		c.Dataset = v
+		return nil
+	}},
+	{"CEP_OUTPUT", "output", func(c *Config, v string) error {
+		c.Output = v
+		return nil
+	}},
+	{"CEP_RETRY_ATTEMPTS", "retry.attempts", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Retry.Attempts = n
+		return err
+	}},
+	{"CEP_CACHE_ENABLED", "cache.enabled", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.Cache.Enabled = b
+		return err
+	}},
+	{"CEP_CACHE_PATH", "cache.path", func(c *Config, v string) error {
+		c.Cache.Path = v
+		return nil
+	}},
+	{"CEP_CACHE_TTL", "cache.ttl", func(c *Config, v string) error { return c.Cache.Ttl.Set(v) }},
+	{"CEP_USER_AGENT", "http.user_agent", func(c *Config, v string) error {
+		c.Http.UserAgent = v
+		return nil
+	}},
+	{"CEP_PROXY", "http.proxy", func(c *Config, v string) error {
+		c.Http.Proxy = v
+		return nil
+	}},
+}
+
+// EnvVars returns the names of the environment variables read by ApplyEnv.
+func EnvVars() []string {
+	names := make([]string, 0, len(envVars))
+	for _, v := range envVars {
+		names = append(names, v.name)
+	}
+	return names
+}
+
+// ApplyEnv overrides the config with the environment variables listed by EnvVars,
+// as returned by lookup, which is usually os.LookupEnv. An overridden field is no
+// longer reported with the line of the file.
+// It returns an error naming the variable if a value cannot be parsed.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, v := range envVars {
+		value, ok := lookup(v.name)
+		if !ok {
+			continue
+		}
+		if err := v.set(c, strings.TrimSpace(value)); err != nil {
+			return errors.New("invalid " + v.name + ": " + value)
+		}
+		c.forget(v.field)
+	}
+	return nil
+}
+
+// Validate checks every setting and returns one FieldError per invalid value,
+// with its line in the config file when it came from there.
+// Providers are checked against the registered providers, plus the "dataset"
+// and "cache" providers, which are registered from the config itself.
+func (c Config) Validate() []*FieldError {
+	var errs []*FieldError
+	fail := func(field, msg string) {
+		errs = append(errs, &FieldError{Path: c.path, Line: c.lines[field], Field: field, Err: errors.New(msg)})
+	}
+
+	known := map[string]bool{usecase.DatasetProviderName: true, usecase.CacheProviderName: true}
+	for _, name := range usecase.Providers() {
+		known[name] = true
+	}
+	if len(c.Providers) == 0 {
+		fail("providers", "at least one provider is required")
+	}
+	for i, name := range c.Providers {
+		if !known[name] {
+			fail("providers["+strconv.Itoa(i)+"]", "unknown provider "+name)
+		}
+	}
+	if name := usecase.DatasetProviderName; slices.Contains(c.Providers, name) && c.Dataset == "" {
+		fail("providers", "provider "+name+" needs a dataset file")
+	}
+	if name := usecase.CacheProviderName; slices.Contains(c.Providers, name) && !c.Cache.Enabled {
+		fail("providers", "provider "+name+" needs the cache enabled")
+	}
+
+	checkDuration(fail, "timeout", c.Timeout, false)
+	checkDuration(fail, "grace", c.Grace, true)
+	checkDuration(fail, "drain", c.Drain, true)
+	for _, name := range sortedKeys(c.Priorities) {
+		if !known[name] {
+			fail("priorities."+name, "unknown provider "+name)
+		}
+	}
+	for _, name := range sortedKeys(c.Weights) {
+		if !known[name] {
+			fail("weights."+name, "unknown provider "+name)
+		} else if w := c.Weights[name]; w < 0 || w > 1 {
+			fail("weights."+name, "weight must be between 0 and 1")
+		}
+	}
+	if c.Output != OutputJson && c.Output != OutputText {
+		fail("output", "output must be "+OutputJson+" or "+OutputText)
+	}
+
+	for _, name := range sortedKeys(c.Endpoints) {
+		e := c.Endpoints[name]
+		field := "endpoints." + name
+		if !known[name] {
+			fail(field, "unknown provider "+name)
+			continue
+		}
+		if e.BaseUrl != "" {
+			if msg := checkUrl(e.BaseUrl); msg != "" {
+				fail(field+".base_url", msg)
+			}
+		}
+		checkDuration(fail, field+".timeout", e.Timeout, true)
+	}
+
+	if c.Retry.Attempts < 1 || c.Retry.Attempts > 10 {
+		fail("retry.attempts", "attempts must be between 1 and 10")
+	}
+	checkDuration(fail, "retry.backoff", c.Retry.Backoff, true)
+	if c.CircuitBreaker.Failures < 0 {
+		fail("circuit_breaker.failures", "failures must not be negative")
+	}
+	checkDuration(fail, "circuit_breaker.cooldown", c.CircuitBreaker.Cooldown, c.CircuitBreaker.Failures == 0)
+
+	if c.Cache.Enabled && c.Cache.Path == "" {
+		fail("cache.path", "an enabled cache needs a path")
+	}
+	checkDuration(fail, "cache.ttl", c.Cache.Ttl, true)
+
+	if c.Http.Proxy != "" {
+		if _, err := usecase.NewClient(usecase.ClientOptions{Proxy: c.Http.Proxy}); err != nil {
+			fail("http.proxy", "proxy must be an absolute url, such as http://proxy.local:3128")
+		}
+	}
+	if c.Http.MaxIdleConnsPerHost < 0 {
+		fail("http.max_idle_conns_per_host", "max_idle_conns_per_host must not be negative")
+	}
+	checkDuration(fail, "http.idle_conn_timeout", c.Http.IdleConnTimeout, true)
+	checkDuration(fail, "http.tls_handshake_timeout", c.Http.TLSHandshakeTimeout, true)
+	checkDuration(fail, "http.response_header_timeout", c.Http.ResponseHeaderTimeout, true)
+	return errs
+}
+
+// ProviderSettings returns the usecase settings of the named provider: its endpoint,
+// if any, and the retry and circuit breaker policies shared by all providers.
+// The client is left to the caller; see ClientOptions.
+func (c Config) ProviderSettings(name string) usecase.ProviderSettings {
+	e := c.Endpoints[name]
+	return usecase.ProviderSettings{
+		URL:     e.BaseUrl,
+		Timeout: e.Timeout.Duration,
+		Retry:   usecase.RetryPolicy{Attempts: c.Retry.Attempts, Backoff: c.Retry.Backoff.Duration},
+		Breaker: usecase.BreakerPolicy{Failures: c.CircuitBreaker.Failures, Cooldown: c.CircuitBreaker.Cooldown.Duration},
+	}
+}
+
+// ClientOptions returns the options of the HTTP clients of the providers.
+func (c Config) ClientOptions() usecase.ClientOptions {
+	return usecase.ClientOptions{
+		UserAgent:             c.Http.UserAgent,
+		Proxy:                 c.Http.Proxy,
+		MaxIdleConnsPerHost:   c.Http.MaxIdleConnsPerHost,
+		IdleConnTimeout:       c.Http.IdleConnTimeout.Duration,
+		TLSHandshakeTimeout:   c.Http.TLSHandshakeTimeout.Duration,
+		ResponseHeaderTimeout: c.Http.ResponseHeaderTimeout.Duration,
+	}
+}
+
+// checkDuration reports an unparsable duration, a negative one, or a zero one
+// when zero is not allowed.
+func checkDuration(fail func(field, msg string), field string, d Duration, zeroAllowed bool) {
+	switch {
+	case d.invalid != "":
+		fail(field, "invalid duration "+strconv.Quote(d.invalid)+", want e.g. \"500ms\" or \"2s\"")
+	case d.Duration < 0:
+		fail(field, "duration must not be negative")
+	case d.Duration == 0 && !zeroAllowed:
+		fail(field, "duration must be positive")
+	}
+}
+
+// checkUrl returns why rawUrl is not a valid provider url template, or "".
+func checkUrl(rawUrl string) string {
+	if !strings.Contains(rawUrl, "{{cep}}") {
+		return "base url must contain {{cep}}"
+	}
+	u, err := url.Parse(strings.Replace(rawUrl, "{{cep}}", "00000000", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "base url must be an absolute http or https url"
+	}
+	return ""
+}
+
+// SetProviders sets the providers from a comma-separated list, such as
+// "brasilapi-v2,viacep", ignoring case and spaces. The list is checked by Validate.
+func (c *Config) SetProviders(list string) {
+	c.Providers = splitList(list)
+	c.forget("providers")
+}
+
+// forget drops the lines of field and of the fields inside it, whose values no
+// longer come from the file.
+func (c *Config) forget(field string) {
+	for f := range c.lines {
+		if f == field || strings.HasPrefix(f, field+".") || strings.HasPrefix(f, field+"[") {
+			delete(c.lines, f)
+		}
+	}
+}
+
+// splitList splits a comma-separated list, trimming and lower-casing the items and dropping empty ones.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// sortedKeys returns the keys of m sorted, so errors come in a stable order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
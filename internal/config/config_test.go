@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadExample(t *testing.T) {
+	c, err := Load("../../config.example.json")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if errs := c.Validate(); len(errs) > 0 {
+		t.Fatalf("Validate() = %v, want no errors", errs)
+	}
+	if c.Timeout.Duration != 2*time.Second || c.Retry.Attempts != 2 {
+		t.Errorf("Load() = %+v, want the settings of the file", c)
+	}
+	s := c.ProviderSettings("viacep")
+	if s.URL != "https://viacep.com.br/ws/{{cep}}/json/" || s.Timeout != 800*time.Millisecond || s.Retry.Attempts != 2 || s.Breaker.Failures != 5 {
+		t.Errorf("ProviderSettings() = %+v", s)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantLine  int
+		wantField string
+	}{
+		{name: "parse only changed settings", data: "{\n\"timeout\": \"3s\"\n}"},
+		{name: "parse syntax error", data: "{\n\"timeout\": \"3s\",\n\"grace\" \"1s\"\n}", wantLine: 3},
+		{name: "parse unknown field", data: "{\n\"timeout\": \"3s\",\n\n\"retries\": 3\n}", wantLine: 4, wantField: "retries"},
+		{name: "parse wrong type", data: "{\n\"retry\": {\n\"attempts\": \"two\"\n}\n}", wantLine: 3, wantField: "retry.attempts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("cep.json", []byte(tt.data))
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("Parse() error = %v, want a *FieldError", err)
+			}
+			if fieldErr.Line != tt.wantLine || fieldErr.Field != tt.wantField {
+				t.Errorf("Parse() error = %v, want line %d field %q", err, tt.wantLine, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "validate defaults",
+			data: "{}",
+		},
+		{
+			name: "validate invalid values",
+			data: `{
+  "providers": ["viacep", "correios"],
+  "timeout": 3,
+  "weights": {"viacep": 2},
+  "output": "xml",
+  "endpoints": {
+    "viacep": {"base_url": "viacep.com.br/ws/"}
+  },
+  "retry": {"attempts": 0}
+}`,
+			want: []string{
+				"cep.json:2: providers[1]: unknown provider correios",
+				`cep.json:3: timeout: invalid duration "3", want e.g. "500ms" or "2s"`,
+				"cep.json:4: weights.viacep: weight must be between 0 and 1",
+				"cep.json:5: output: output must be json or text",
+				"cep.json:7: endpoints.viacep.base_url: base url must contain {{cep}}",
+				"cep.json:9: retry.attempts: attempts must be between 1 and 10",
+			},
+		},
+		{
+			name: "validate local providers need their sources",
+			data: `{"providers": ["cache", "dataset"]}`,
+			want: []string{
+				"cep.json:1: providers: provider dataset needs a dataset file",
+				"cep.json:1: providers: provider cache needs the cache enabled",
+			},
+		},
+		{
+			name: "validate circuit breaker needs a cooldown",
+			data: `{"circuit_breaker": {"failures": 3}}`,
+			want: []string{"cep.json: circuit_breaker.cooldown: duration must be positive"},
+		},
+		{
+			name: "validate http client",
+			data: `{"http": {"proxy": "proxy.local:3128", "max_idle_conns_per_host": -1, "response_header_timeout": "-2s"}}`,
+			want: []string{
+				"cep.json:1: http.proxy: proxy must be an absolute url, such as http://proxy.local:3128",
+				"cep.json:1: http.max_idle_conns_per_host: max_idle_conns_per_host must not be negative",
+				"cep.json:1: http.response_header_timeout: duration must not be negative",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse("cep.json", []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []string
+			for _, err := range c.Validate() {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"CEP_PROVIDERS":      "Viacep, postmon",
+		"CEP_TIMEOUT":        "5s",
+		"CEP_CACHE_ENABLED":  "true",
+		"CEP_RETRY_ATTEMPTS": "3",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+	c, err := Parse("cep.json", []byte("{\n\"timeout\": \"-1s\"\n}"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := c.ApplyEnv(lookup); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if !reflect.DeepEqual(c.Providers, []string{"viacep", "postmon"}) || c.Timeout.Duration != 5*time.Second ||
+		!c.Cache.Enabled || c.Retry.Attempts != 3 {
+		t.Errorf("ApplyEnv() = %+v", c)
+	}
+	if errs := c.Validate(); len(errs) > 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+
+	env = map[string]string{"CEP_TIMEOUT": "soon"}
+	if err := c.ApplyEnv(lookup); err == nil {
+		t.Errorf("ApplyEnv() error = nil, want an error for CEP_TIMEOUT")
+	}
+}
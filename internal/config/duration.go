@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Duration is a time.Duration written in the config as a string such as "500ms" or "2s".
+// A value that does not parse is kept, instead of failing the whole file, so
+// Validate can report it with its line.
+type Duration struct {
+	time.Duration
+	invalid string
+}
+
+// Set parses s with time.ParseDuration.
+// It returns an error, and leaves the duration unchanged, if s does not parse.
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration{Duration: v}
+	return nil
+}
+
+// UnmarshalJSON decodes a duration string, or null for zero.
+// Any other value, like a number without unit, is kept as invalid.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Duration{}
+		return nil
+	}
+	s := string(data)
+	if err := json.Unmarshal(data, &s); err != nil {
+		*d = Duration{invalid: s}
+		return nil
+	}
+	if err := d.Set(s); err != nil {
+		*d = Duration{invalid: s}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the duration as a string such as "1m30s".
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
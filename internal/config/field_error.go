@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// FieldError is an error in one field of the config. Line is the line of the
+// field in the config file, or 0 if the value did not come from the file.
+type FieldError struct {
+	Path  string
+	Line  int
+	Field string
+	Err   error
+}
+
+// Error returns the error prefixed by the file and line, when known, and the field,
+// as in "cep.json:12: retry.attempts: attempts must be between 1 and 10".
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		if e.Line > 0 {
+			b.WriteString(":" + strconv.Itoa(e.Line))
+		}
+		b.WriteString(": ")
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// decodeError turns an error of the JSON decoder into a *FieldError with the line
+// where decoding stopped, when the decoder tells it.
+func decodeError(path string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &FieldError{Path: path, Line: lineAt(data, syntaxErr.Offset-1), Err: err}
+	case errors.As(err, &typeErr):
+		line, ok := indexLines(data)[typeErr.Field]
+		if !ok {
+			line = lineAt(data, typeErr.Offset-1)
+		}
+		return &FieldError{Path: path, Line: line, Field: typeErr.Field,
+			Err: errors.New("cannot use " + typeErr.Value + " as " + typeErr.Type.String())}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		return &FieldError{Path: path, Line: keyLine(data, field), Field: field, Err: errors.New("unknown field")}
+	}
+	return &FieldError{Path: path, Err: err}
+}
+
+// indexLines maps the path of every field of the JSON document to its line.
+// Object fields are joined by '.', as in "endpoints.viacep.base_url", and array
+// items are indexed, as in "providers[1]". It stops at the first syntax error.
+func indexLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) bool
+	walk = func(path string) bool {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if _, ok := lines[path]; !ok && path != "" {
+			lines[path] = lineAt(data, offset)
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return true
+		}
+		for i := 0; dec.More(); i++ {
+			if delim == '[' {
+				if !walk(path + "[" + strconv.Itoa(i) + "]") {
+					return false
+				}
+				continue
+			}
+			keyOffset := dec.InputOffset()
+			key, err := dec.Token()
+			if err != nil {
+				return false
+			}
+			field := key.(string)
+			if path != "" {
+				field = path + "." + field
+			}
+			lines[field] = lineAt(data, keyOffset)
+			if !walk(field) {
+				return false
+			}
+		}
+		_, err = dec.Token()
+		return err == nil
+	}
+	walk("")
+	return lines
+}
+
+// keyLine returns the line of the first "key": in data, or 0.
+func keyLine(data []byte, key string) int {
+	i := bytes.Index(data, []byte(strconv.Quote(key)))
+	if i < 0 {
+		return 0
+	}
+	return lineAt(data, int64(i))
+}
+
+// lineAt returns the 1-based line of the first token at or after offset, skipping
+// the whitespace and separators the decoder leaves before it.
+func lineAt(data []byte, offset int64) int {
+	offset = max(0, min(offset, int64(len(data))))
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,96 @@
+package dataset
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// Dataset is an in-memory index of ceps loaded from a local export of the
+// Correios DNE (address database). Lookups are map accesses, so the dataset
+// answers in microseconds. A Dataset is read-only after loading and safe for
+// concurrent use.
+type Dataset struct {
+	ceps map[string]dto.Cep
+}
+
+// RowError is the error found in one row of the dataset file.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Error returns the line number followed by the error.
+func (e *RowError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// newDataset creates an empty Dataset.
+func newDataset() *Dataset {
+	return &Dataset{ceps: map[string]dto.Cep{}}
+}
+
+// add validates the cep with dto.Cep.Validate and indexes it.
+// It returns an error if the cep is invalid or already in the dataset.
+func (d *Dataset) add(cep dto.Cep) error {
+	cep.CityLevel = cep.IsCityLevel()
+	if err := cep.Validate(); err != nil {
+		return err
+	}
+	key := Key(cep.Cep)
+	if _, ok := d.ceps[key]; ok {
+		return errors.New("duplicated cep " + cep.Cep)
+	}
+	d.ceps[key] = cep
+	return nil
+}
+
+// Lookup returns the Cep indexed under cep, which may be written with or without '-'.
+// If the cep is not in the dataset, it returns an empty Cep and false.
+func (d *Dataset) Lookup(cep string) (dto.Cep, bool) {
+	c, ok := d.ceps[Key(cep)]
+	return c, ok
+}
+
+// Filter returns the ceps for which match returns true, sorted by cep, up to limit
+// ceps; a limit of 0 or less returns all of them.
+func (d *Dataset) Filter(match func(dto.Cep) bool, limit int) []dto.Cep {
+	keys := make([]string, 0, len(d.ceps))
+	for k := range d.ceps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var found []dto.Cep
+	for _, k := range keys {
+		if limit > 0 && len(found) == limit {
+			break
+		}
+		if c := d.ceps[k]; match(c) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// All returns the ceps of the dataset, sorted by cep.
+func (d *Dataset) All() []dto.Cep {
+	return d.Filter(func(dto.Cep) bool { return true }, 0)
+}
+
+// Len returns the number of ceps in the dataset.
+func (d *Dataset) Len() int {
+	return len(d.ceps)
+}
+
+// Key returns the index key of a cep: its 8 digits, without '-'.
+func Key(cep string) string {
+	return strings.Replace(strings.TrimSpace(cep), "-", "", 1)
+}
@@ -0,0 +1,161 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func TestLoadCsv(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantLen       int
+		wantRowErrors []int
+		wantErr       bool
+	}{
+		{
+			name: "load csv in english",
+			data: "cep,state,city,neighborhood,street\n" +
+				"39408078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n" +
+				"39270-000,MG,Lassance,,\n",
+			wantLen: 2,
+		},
+		{
+			name: "load csv dne columns with at sign",
+			data: "\ufeffCEP@UF@LOCALIDADE@BAIRRO@LOGRADOURO@COMPLEMENTO\n" +
+				"39408078@MG@Montes Claros@Ibituruna@Avenida Herlindo Silveira@até 499/500\n",
+			wantLen: 1,
+		},
+		{
+			name: "load csv with invalid rows",
+			data: "cep;uf;cidade;bairro;logradouro\n" +
+				"39408078;MG;Montes Claros;Ibituruna;Avenida Herlindo Silveira\n" +
+				"00000000;SP;São Paulo;Sé;Praça da Sé\n" +
+				"39408078;MG;Montes Claros;Ibituruna;Avenida Herlindo Silveira\n" +
+				"39408079;MG;Montes Claros;Ibituruna;\n",
+			wantLen:       1,
+			wantRowErrors: []int{3, 4, 5},
+		},
+		{
+			name: "load csv with malformed rows",
+			data: "cep;uf;cidade;bairro;logradouro\n" +
+				"\"3940\"8079;MG;Montes Claros;Ibituruna;Avenida Herlindo Silveira\n" +
+				"39408078;MG;Montes Claros;Ibituruna;Avenida Herlindo Silveira\n" +
+				"01001000;SP;\"São Paulo;Sé;Praça da Sé\n",
+			wantLen:       1,
+			wantRowErrors: []int{2, 4},
+		},
+		{
+			name: "load csv in latin1",
+			data: "cep,uf,cidade,bairro,logradouro\n" +
+				"01001000,SP,S\xe3o Paulo,S\xe9,Pra\xe7a da S\xe9\n",
+			wantLen: 1,
+		},
+		{
+			name:    "load csv without required column",
+			data:    "cep,uf,cidade\n39408078,MG,Montes Claros\n",
+			wantErr: true,
+		},
+		{
+			name:    "load csv empty",
+			data:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rowErrors, err := LoadCsv(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCsv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if d.Len() != tt.wantLen {
+				t.Errorf("LoadCsv() loaded %d ceps, want %d", d.Len(), tt.wantLen)
+			}
+			if len(rowErrors) != len(tt.wantRowErrors) {
+				t.Fatalf("LoadCsv() row errors = %v, want lines %v", rowErrors, tt.wantRowErrors)
+			}
+			for i, rowErr := range rowErrors {
+				if rowErr.Line != tt.wantRowErrors[i] {
+					t.Errorf("LoadCsv() row error %d at line %d, want %d", i, rowErr.Line, tt.wantRowErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFixedWidth(t *testing.T) {
+	line := func(cep, uf, city, neighborhood, street string) string {
+		pad := func(s string, n int) string { return s + strings.Repeat(" ", n-len(s)) }
+		return pad(cep, 8) + pad(uf, 2) + pad(city, 72) + pad(neighborhood, 72) + pad(street, 100) + "\n"
+	}
+	data := line("39408078", "MG", "Montes Claros", "Ibituruna", "Avenida Herlindo Silveira") +
+		"\n" +
+		line("39270000", "MG", "Lassance", "", "") +
+		line("3940807", "MG", "Montes Claros", "Ibituruna", "Avenida Herlindo Silveira")
+
+	d, rowErrors, err := LoadFixedWidth(strings.NewReader(data), DefaultFixedWidthLayout)
+	if err != nil {
+		t.Fatalf("LoadFixedWidth() error = %v", err)
+	}
+	if d.Len() != 2 {
+		t.Errorf("LoadFixedWidth() loaded %d ceps, want 2", d.Len())
+	}
+	if len(rowErrors) != 1 || rowErrors[0].Line != 4 {
+		t.Errorf("LoadFixedWidth() row errors = %v, want one at line 4", rowErrors)
+	}
+	got, ok := d.Lookup("39270-000")
+	want := dto.Cep{Cep: "39270000", State: "MG", City: "Lassance", CityLevel: true}
+	if !ok || got != want {
+		t.Errorf("Dataset.Lookup() = %v, %v, want %v", got, ok, want)
+	}
+}
+
+func TestDataset_Lookup(t *testing.T) {
+	d, _, err := LoadCsv(strings.NewReader("cep,uf,cidade,bairro,logradouro\n39408-078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		cep    string
+		wantOk bool
+	}{
+		{name: "lookup without dash", cep: "39408078", wantOk: true},
+		{name: "lookup with dash", cep: "39408-078", wantOk: true},
+		{name: "lookup not found", cep: "39408079", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := d.Lookup(tt.cep)
+			if ok != tt.wantOk {
+				t.Errorf("Dataset.Lookup() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got.Street != "Avenida Herlindo Silveira" {
+				t.Errorf("Dataset.Lookup() = %v", got)
+			}
+		})
+	}
+}
+
+func BenchmarkDataset_Lookup(b *testing.B) {
+	var csv strings.Builder
+	csv.WriteString("cep,uf,cidade,bairro,logradouro\n")
+	for i := range 10000 {
+		fmt.Fprintf(&csv, "3%07d,MG,Montes Claros,Ibituruna,Rua %d\n", i, i)
+	}
+	d, _, err := LoadCsv(strings.NewReader(csv.String()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		d.Lookup(fmt.Sprintf("3%07d", i%10000))
+	}
+}
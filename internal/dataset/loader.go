@@ -0,0 +1,213 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// columnAliases maps the accepted CSV header names to the dto.Cep field they fill.
+var columnAliases = map[string]string{
+	"cep":          "cep",
+	"uf":           "state",
+	"state":        "state",
+	"estado":       "state",
+	"city":         "city",
+	"cidade":       "city",
+	"localidade":   "city",
+	"municipio":    "city",
+	"neighborhood": "neighborhood",
+	"bairro":       "neighborhood",
+	"street":       "street",
+	"logradouro":   "street",
+}
+
+// csvDelimiters are the delimiters recognized in the header line, in order of preference.
+// The DNE export uses '@'.
+var csvDelimiters = []rune{'@', ';', '|', '\t', ','}
+
+// FixedWidthField is a field of a fixed-width record. Start is the 0-based
+// byte offset of the field in the line and Length its width in bytes.
+type FixedWidthField struct {
+	Name   string
+	Start  int
+	Length int
+}
+
+// DefaultFixedWidthLayout is the layout of the fixed-width DNE export:
+// cep (8), uf (2), city (72), neighborhood (72) and street (100).
+var DefaultFixedWidthLayout = []FixedWidthField{
+	{Name: "cep", Start: 0, Length: 8},
+	{Name: "state", Start: 8, Length: 2},
+	{Name: "city", Start: 10, Length: 72},
+	{Name: "neighborhood", Start: 82, Length: 72},
+	{Name: "street", Start: 154, Length: 100},
+}
+
+// Load opens the dataset file at path and loads it. Files ending in .txt or .dat
+// are read as fixed-width records with DefaultFixedWidthLayout; any other file
+// is read as CSV.
+// It returns the dataset with every valid row, the errors of the invalid rows,
+// which are skipped, and an error if the file cannot be read at all.
+func Load(path string) (*Dataset, []RowError, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".dat":
+		return LoadFixedWidth(f, DefaultFixedWidthLayout)
+	default:
+		return LoadCsv(f)
+	}
+}
+
+// LoadCsv loads a CSV dataset. The first line is a header naming the columns,
+// in English (cep, state, city, neighborhood, street) or as in the DNE
+// (cep, uf, localidade, bairro, logradouro); other columns are ignored.
+// The delimiter is detected from the header among '@', ';', '|', tab and ','.
+// Fields that are not valid UTF-8 are decoded from Windows-1252.
+// Every row is validated with dto.Cep.Validate.
+// It returns the dataset with every valid row, the errors of the invalid rows,
+// which are skipped, and an error if the header is missing or incomplete or the
+// input cannot be read.
+func LoadCsv(r io.Reader) (*Dataset, []RowError, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(br)
+	reader.Comma = detectDelimiter(string(header))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("missing header: " + err.Error())
+	}
+	index := map[string]int{}
+	for i, name := range columns {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := columnAliases[name]; ok {
+			index[field] = i
+		}
+	}
+	for _, field := range []string{"cep", "state", "city", "neighborhood", "street"} {
+		if _, ok := index[field]; !ok {
+			return nil, nil, errors.New("header has no column for " + field)
+		}
+	}
+
+	d := newDataset()
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// A malformed row, such as one with a stray quote, has no field positions,
+		// so its line comes from the parse error.
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		value := func(field string) string {
+			if i := index[field]; i < len(record) {
+				return toUtf8(strings.TrimSpace(record[i]))
+			}
+			return ""
+		}
+		cep := dto.Cep{
+			Cep:          value("cep"),
+			State:        value("state"),
+			City:         value("city"),
+			Neighborhood: value("neighborhood"),
+			Street:       value("street"),
+		}
+		if err := d.add(cep); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: err})
+		}
+	}
+	return d, rowErrors, nil
+}
+
+// LoadFixedWidth loads a dataset of fixed-width records, one per line, described
+// by layout. Offsets are in bytes, as in the single-byte encoded DNE export. Blank lines are skipped and every row is validated with dto.Cep.Validate.
+// It returns the dataset with every valid row, the errors of the invalid rows,
+// which are skipped, and an error if the input cannot be read.
+func LoadFixedWidth(r io.Reader, layout []FixedWidthField) (*Dataset, []RowError, error) {
+	d := newDataset()
+	var rowErrors []RowError
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := map[string]string{}
+		for _, f := range layout {
+			if f.Start >= len(text) {
+				continue
+			}
+			end := min(f.Start+f.Length, len(text))
+			fields[f.Name] = toUtf8(strings.TrimSpace(text[f.Start:end]))
+		}
+		cep := dto.Cep{
+			Cep:          fields["cep"],
+			State:        fields["state"],
+			City:         fields["city"],
+			Neighborhood: fields["neighborhood"],
+			Street:       fields["street"],
+		}
+		if err := d.add(cep); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: err})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return d, rowErrors, nil
+}
+
+// toUtf8 returns s unchanged if it is valid UTF-8, or decoded from Windows-1252,
+// the encoding of the DNE export, otherwise.
+func toUtf8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	decoded, err := charmap.Windows1252.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// detectDelimiter returns the first recognized delimiter found in the first line of s,
+// or ',' if there is none.
+func detectDelimiter(s string) rune {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	for _, d := range csvDelimiters {
+		if strings.ContainsRune(s, d) {
+			return d
+		}
+	}
+	return ','
+}
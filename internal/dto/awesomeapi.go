@@ -0,0 +1,141 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+type Awesomeapi struct {
+	Cep         string     `json:"cep"`
+	AddressType string     `json:"address_type"`
+	AddressName string     `json:"address_name"`
+	Address     string     `json:"address"`
+	State       string     `json:"state"`
+	District    string     `json:"district"`
+	Lat         Coordinate `json:"lat"`
+	Lng         Coordinate `json:"lng"`
+	City        string     `json:"city"`
+	CityIbge    string     `json:"city_ibge"`
+	Ddd         string     `json:"ddd"`
+}
+
+// NewAwesomeapi creates a new Awesomeapi instance and validates it.
+// It returns an error if the validation fails.
+//
+// address is the full street, such as "Avenida Herlindo Silveira", which AwesomeAPI
+// also splits into addressType and addressName.
+func NewAwesomeapi(cep, addressType, addressName, address, state, district, lat, lng, city, cityIbge, ddd string) (*Awesomeapi, error) {
+	a := &Awesomeapi{
+		Cep:         cep,
+		AddressType: addressType,
+		AddressName: addressName,
+		Address:     address,
+		State:       state,
+		District:    district,
+		Lat:         Coordinate(lat),
+		Lng:         Coordinate(lng),
+		City:        city,
+		CityIbge:    cityIbge,
+		Ddd:         ddd,
+	}
+	err := a.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewAwesomeapiFromJson creates a new Awesomeapi instance from a JSON string and validates it.
+// It returns the created Awesomeapi instance or an error if the JSON is invalid or validation fails.
+func NewAwesomeapiFromJson(jsonString string) (*Awesomeapi, error) {
+	var a Awesomeapi
+	err := DecodeJson([]byte(jsonString), &a)
+	if err != nil {
+		return nil, err
+	}
+	err = a.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Validate checks the fields of the Awesomeapi struct for validity.
+// It verifies that the cep is valid and belongs to some state range, the state is a
+// recognized short state name, the city ibge code, when present, belongs to that state,
+// the city is not empty, district and address are either both filled or both empty,
+// and lat and lng are either both empty or a point inside the Brazilian territory.
+func (a *Awesomeapi) Validate() error {
+	if _, err := shared.ValidateCepWithoutDash(a.Cep); err != nil {
+		return err
+	}
+	if _, err := shared.ValidateCepRange(a.Cep); err != nil {
+		return err
+	}
+	state, ok := shared.StateByUf(a.State)
+	if !ok {
+		return errors.New("state not found")
+	}
+	if a.CityIbge != "" && !strings.HasPrefix(a.CityIbge, state.IbgeCode) {
+		return errors.New("city_ibge does not belong to state")
+	}
+	if err := validateLocality(a.City, a.District, a.Address); err != nil {
+		return err
+	}
+	return validateCoordinates(a.Lat, a.Lng)
+}
+
+// IsCityLevel reports whether the Awesomeapi record covers a whole city,
+// that is, it has no district and no address.
+func (a *Awesomeapi) IsCityLevel() bool {
+	return isCityLevel(a.District, a.Address)
+}
+
+// Coordinates returns the latitude and longitude of the cep.
+// ok is false when AwesomeAPI did not send coordinates or they are not numbers.
+func (a *Awesomeapi) Coordinates() (latitude, longitude float64, ok bool) {
+	return parseCoordinates(a.Lat, a.Lng)
+}
+
+type AwesomeapiError struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// NewAwesomeapiErrorFromJson creates a new AwesomeapiError instance from the JSON body
+// AwesomeAPI sends with a 400 or 404 status, and validates it.
+// It returns the created AwesomeapiError instance or an error if the JSON is invalid or validation fails.
+func NewAwesomeapiErrorFromJson(jsonString string) (*AwesomeapiError, error) {
+	var a AwesomeapiError
+	err := DecodeJson([]byte(jsonString), &a)
+	if err != nil {
+		return nil, err
+	}
+	err = a.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Validate checks that the AwesomeapiError has a code and a message.
+func (a *AwesomeapiError) Validate() error {
+	if a.Code == "" || a.Message == "" {
+		return errors.New("code and message must not be empty")
+	}
+	return nil
+}
+
+// ToUpstreamError converts the AwesomeapiError into an UpstreamError for the given
+// service name and HTTP status code.
+func (a *AwesomeapiError) ToUpstreamError(service string, statusCode int) *UpstreamError {
+	return &UpstreamError{
+		Service:    service,
+		StatusCode: statusCode,
+		Message:    a.Message,
+		Type:       a.Code,
+	}
+}
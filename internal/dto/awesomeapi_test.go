@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewAwesomeapi(t *testing.T) {
+	got, err := NewAwesomeapi("39408078", "Avenida", "Herlindo Silveira", "Avenida Herlindo Silveira", "MG", "Ibituruna", "-16.7350934", "-43.8772265", "Montes Claros", "3143302", "38")
+	if err != nil {
+		t.Fatalf("NewAwesomeapi() error = %v", err)
+	}
+	want := &Awesomeapi{
+		Cep:         "39408078",
+		AddressType: "Avenida",
+		AddressName: "Herlindo Silveira",
+		Address:     "Avenida Herlindo Silveira",
+		State:       "MG",
+		District:    "Ibituruna",
+		Lat:         "-16.7350934",
+		Lng:         "-43.8772265",
+		City:        "Montes Claros",
+		CityIbge:    "3143302",
+		Ddd:         "38",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewAwesomeapi() = %v, want %v", got, want)
+	}
+	if _, err := NewAwesomeapi("3940807", "", "", "", "MG", "", "", "", "Montes Claros", "", ""); err == nil {
+		t.Errorf("NewAwesomeapi() error = nil, want error for invalid cep")
+	}
+}
+
+func TestNewAwesomeapiFromJson(t *testing.T) {
+	b, err := os.ReadFile("../../responses/awesomeapi.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewAwesomeapiFromJson(string(b))
+	if err != nil {
+		t.Fatalf("NewAwesomeapiFromJson() error = %v", err)
+	}
+	if got.Address != "Avenida Herlindo Silveira" || got.District != "Ibituruna" || got.City != "Montes Claros" || got.State != "MG" {
+		t.Errorf("NewAwesomeapiFromJson() = %v", got)
+	}
+	lat, lon, ok := got.Coordinates()
+	if !ok || lat != -16.7350934 || lon != -43.8772265 {
+		t.Errorf("Awesomeapi.Coordinates() = %v, %v, %v", lat, lon, ok)
+	}
+	if _, err := NewAwesomeapiFromJson(`{"cep":39408078}`); err == nil {
+		t.Errorf("NewAwesomeapiFromJson() error = nil, want error for invalid json")
+	}
+}
+
+func TestAwesomeapi_Validate(t *testing.T) {
+	valid := Awesomeapi{
+		Cep:      "39408078",
+		Address:  "Avenida Herlindo Silveira",
+		State:    "MG",
+		District: "Ibituruna",
+		City:     "Montes Claros",
+		CityIbge: "3143302",
+	}
+	tests := []struct {
+		name    string
+		change  func(a *Awesomeapi)
+		wantErr bool
+	}{
+		{name: "awesomeapi validate", change: func(a *Awesomeapi) {}, wantErr: false},
+		{name: "awesomeapi city level", change: func(a *Awesomeapi) { a.Address, a.District = "", "" }, wantErr: false},
+		{name: "awesomeapi invalid cep", change: func(a *Awesomeapi) { a.Cep = "39408-078" }, wantErr: true},
+		{name: "awesomeapi invalid state", change: func(a *Awesomeapi) { a.State = "MM" }, wantErr: true},
+		{name: "awesomeapi city ibge of another state", change: func(a *Awesomeapi) { a.CityIbge = "3550308" }, wantErr: true},
+		{name: "awesomeapi invalid city", change: func(a *Awesomeapi) { a.City = "" }, wantErr: true},
+		{name: "awesomeapi invalid district", change: func(a *Awesomeapi) { a.District = "" }, wantErr: true},
+		{name: "awesomeapi only latitude", change: func(a *Awesomeapi) { a.Lat = "-16.7350934" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid
+			tt.change(&a)
+			if err := a.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Awesomeapi.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewAwesomeapiErrorFromJson(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		wantCode    string
+		wantMessage string
+	}{
+		{name: "awesomeapi 404 fixture", fixture: "awesomeapi.404.json", wantCode: "not_found", wantMessage: "O CEP 39408079 nao foi encontrado"},
+		{name: "awesomeapi 400 fixture", fixture: "awesomeapi.400.json", wantCode: "invalid", wantMessage: "O CEP 394080788 informado é inválido"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := os.ReadFile("../../responses/" + tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := NewAwesomeapiErrorFromJson(string(b))
+			if err != nil {
+				t.Fatalf("NewAwesomeapiErrorFromJson() error = %v", err)
+			}
+			if got.Code != tt.wantCode || got.Message != tt.wantMessage {
+				t.Errorf("NewAwesomeapiErrorFromJson() = %v, want %v %v", got, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package dto
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
@@ -22,10 +21,10 @@ type Brasilapi struct {
 // NewBrasilapi creates a new Brasilapi instance and validates it.
 // It returns an error if the validation fails.
 //
-// cep must be a valid brazilian cep.
+// cep must be a valid brazilian cep inside some state range.
 // state must be a valid short state name.
 // service must be one of the allowed services.
-// city, neighborhood and street must not be empty.
+// city must not be empty; neighborhood and street must be both filled or both empty.
 func NewBrasilapi(cep, state, city, neighborhood, street, service string) (*Brasilapi, error) {
 
 	b := &Brasilapi{
@@ -45,10 +44,11 @@ func NewBrasilapi(cep, state, city, neighborhood, street, service string) (*Bras
 }
 
 // NewBrasilapiFromJson creates a new Brasilapi instance from a JSON string and validates it.
+// The body is decoded with the tolerant DecodeJson.
 // It returns the created Brasilapi instance or an error if the JSON is invalid or validation fails.
 func NewBrasilapiFromJson(jsonString string) (*Brasilapi, error) {
 	var b Brasilapi
-	err := json.Unmarshal([]byte(jsonString), &b)
+	err := DecodeJson([]byte(jsonString), &b)
 	if err != nil {
 		return nil, err
 	}
@@ -60,21 +60,28 @@ func NewBrasilapiFromJson(jsonString string) (*Brasilapi, error) {
 }
 
 // Validate checks the fields of the Brasilapi struct for validity.
-// It verifies that the cep is valid, the state is a recognized short state name,
-// the service is one of the allowed services, and that the city, neighborhood,
-// and street fields are not empty. If any validation fails, it returns an error.
+// It verifies that the cep is valid and belongs to some state range, the state is a
+// recognized short state name, the service is one of the allowed services, that the
+// city is not empty and that neighborhood and street are either both filled or, for a city-level cep, both empty.
+// If any validation fails, it returns an error.
 func (b *Brasilapi) Validate() error {
 	if _, err := shared.ValidateCepWithoutDash(b.Cep); err != nil {
 		return err
 	}
+	if _, err := shared.ValidateCepRange(b.Cep); err != nil {
+		return err
+	}
 	if !shared.ValidateStateShort(b.State) {
 		return errors.New("state not found")
 	}
 	if !slices.Contains(services, b.Service) {
 		return errors.New("service not found")
 	}
-	if b.City == "" || b.Neighborhood == "" || b.Street == "" {
-		return errors.New("city, neighborhood and street must not be empty")
-	}
-	return nil
+	return validateLocality(b.City, b.Neighborhood, b.Street)
+}
+
+// IsCityLevel reports whether the Brasilapi record covers a whole city,
+// that is, it has no neighborhood and no street.
+func (b *Brasilapi) IsCityLevel() bool {
+	return isCityLevel(b.Neighborhood, b.Street)
 }
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+type BrasilapiError struct {
+	Message string         `json:"message"`
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Errors  []ServiceError `json:"errors"`
+}
+
+// NewBrasilapiErrorFromJson creates a new BrasilapiError instance from the JSON body
+// Brasilapi sends with a 400 or 404 status, and validates it.
+// It returns the created BrasilapiError instance or an error if the JSON is invalid or validation fails.
+func NewBrasilapiErrorFromJson(jsonString string) (*BrasilapiError, error) {
+	var b BrasilapiError
+	err := json.Unmarshal([]byte(jsonString), &b)
+	if err != nil {
+		return nil, err
+	}
+	err = b.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Validate checks that the BrasilapiError has a message and that every
+// sub-service error names its service.
+func (b *BrasilapiError) Validate() error {
+	if b.Message == "" {
+		return errors.New("message must not be empty")
+	}
+	for _, se := range b.Errors {
+		if se.Service == "" {
+			return errors.New("service of sub-service error must not be empty")
+		}
+	}
+	return nil
+}
+
+// ToUpstreamError converts the BrasilapiError into an UpstreamError for the given
+// service name and HTTP status code.
+func (b *BrasilapiError) ToUpstreamError(service string, statusCode int) *UpstreamError {
+	return &UpstreamError{
+		Service:    service,
+		StatusCode: statusCode,
+		Message:    b.Message,
+		Type:       b.Type,
+		Name:       b.Name,
+		Errors:     append([]ServiceError(nil), b.Errors...),
+	}
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewBrasilapiErrorFromJson(t *testing.T) {
+	tests := []struct {
+		name         string
+		fixture      string
+		jsonString   string
+		wantMessage  string
+		wantType     string
+		wantServices []string
+		wantErr      bool
+	}{
+		{
+			name:         "brasilapi 400 fixture",
+			fixture:      "../../responses/brasilapi.400.json",
+			wantMessage:  "CEP deve conter exatamente 8 caracteres.",
+			wantType:     "validation_error",
+			wantServices: []string{"cep_validation"},
+			wantErr:      false,
+		},
+		{
+			name:         "brasilapi 404 fixture",
+			fixture:      "../../responses/brasilapi.404.json",
+			wantMessage:  "Todos os serviços de CEP retornaram erro.",
+			wantType:     "service_error",
+			wantServices: []string{"correios", "viacep", "widenet", "correios-alt"},
+			wantErr:      false,
+		},
+		{
+			name:       "brasilapi error without message",
+			jsonString: `{"type":"service_error","errors":[]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "brasilapi error with invalid json",
+			jsonString: `<html></html>`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonString := tt.jsonString
+			if tt.fixture != "" {
+				b, err := os.ReadFile(tt.fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				jsonString = string(b)
+			}
+			got, err := NewBrasilapiErrorFromJson(jsonString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBrasilapiErrorFromJson() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Message != tt.wantMessage || got.Type != tt.wantType {
+				t.Errorf("NewBrasilapiErrorFromJson() = %v, want message %v and type %v", got, tt.wantMessage, tt.wantType)
+			}
+			upstreamErr := got.ToUpstreamError("Brasilapi", 404)
+			if services := upstreamErr.FailingServices(); !reflect.DeepEqual(services, tt.wantServices) {
+				t.Errorf("UpstreamError.FailingServices() = %v, want %v", services, tt.wantServices)
+			}
+		})
+	}
+}
@@ -213,6 +213,28 @@ func TestBrasilapi_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "brasilapi cep out of any state range",
+			b: &Brasilapi{
+				Cep:          "00000000",
+				State:        "SP",
+				City:         "São Paulo",
+				Neighborhood: "Sé",
+				Street:       "Praça da Sé",
+				Service:      "open-cep",
+			},
+			wantErr: true,
+		},
+		{
+			name: "brasilapi city level",
+			b: &Brasilapi{
+				Cep:     "39270000",
+				State:   "MG",
+				City:    "Lassance",
+				Service: "open-cep",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -222,3 +244,41 @@ func TestBrasilapi_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestBrasilapi_IsCityLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *Brasilapi
+		want bool
+	}{
+		{
+			name: "street level brasilapi",
+			b: &Brasilapi{
+				Cep:          "39408078",
+				State:        "MG",
+				City:         "Montes Claros",
+				Neighborhood: "Ibituruna",
+				Street:       "Avenida Herlindo Silveira",
+				Service:      "open-cep",
+			},
+			want: false,
+		},
+		{
+			name: "city level brasilapi",
+			b: &Brasilapi{
+				Cep:     "39270000",
+				State:   "MG",
+				City:    "Lassance",
+				Service: "open-cep",
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.IsCityLevel(); got != tt.want {
+				t.Errorf("Brasilapi.IsCityLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"errors"
+)
+
+type BrasilapiV2Coordinates struct {
+	Longitude Coordinate `json:"longitude"`
+	Latitude  Coordinate `json:"latitude"`
+}
+
+type BrasilapiV2Location struct {
+	Type        string                 `json:"type"`
+	Coordinates BrasilapiV2Coordinates `json:"coordinates"`
+}
+
+type BrasilapiV2 struct {
+	Brasilapi
+	Location BrasilapiV2Location `json:"location"`
+}
+
+// NewBrasilapiV2 creates a new BrasilapiV2 instance and validates it.
+// It returns an error if the validation fails.
+//
+// The address fields follow the same rules as NewBrasilapi.
+// latitude and longitude may be both empty; otherwise they must be
+// numbers inside the Brazilian territory.
+func NewBrasilapiV2(cep, state, city, neighborhood, street, service, latitude, longitude string) (*BrasilapiV2, error) {
+	b := &BrasilapiV2{
+		Brasilapi: Brasilapi{
+			Cep:          cep,
+			State:        state,
+			City:         city,
+			Neighborhood: neighborhood,
+			Street:       street,
+			Service:      service,
+		},
+		Location: BrasilapiV2Location{
+			Type: "Point",
+			Coordinates: BrasilapiV2Coordinates{
+				Longitude: Coordinate(longitude),
+				Latitude:  Coordinate(latitude),
+			},
+		},
+	}
+
+	err := b.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewBrasilapiV2FromJson creates a new BrasilapiV2 instance from a JSON string and validates it.
+// The body is decoded with the tolerant DecodeJson.
+// It returns the created BrasilapiV2 instance or an error if the JSON is invalid or validation fails.
+func NewBrasilapiV2FromJson(jsonString string) (*BrasilapiV2, error) {
+	var b BrasilapiV2
+	err := DecodeJson([]byte(jsonString), &b)
+	if err != nil {
+		return nil, err
+	}
+	err = b.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Validate checks the address fields with the same rules as Brasilapi.Validate and then
+// checks the location. A location without coordinates is valid, since Brasilapi does not
+// know the coordinates of every cep. When coordinates are present, the location type must
+// be "Point", both latitude and longitude must be filled and they must be numbers inside
+// the Brazilian territory.
+func (b *BrasilapiV2) Validate() error {
+	if err := b.Brasilapi.Validate(); err != nil {
+		return err
+	}
+	c := b.Location.Coordinates
+	if (c.Latitude != "" || c.Longitude != "") && b.Location.Type != "Point" {
+		return errors.New("location type must be Point")
+	}
+	return validateCoordinates(c.Latitude, c.Longitude)
+}
+
+// Coordinates returns the latitude and longitude of the location.
+// ok is false when Brasilapi did not send coordinates or they are not numbers.
+func (b *BrasilapiV2) Coordinates() (latitude, longitude float64, ok bool) {
+	return parseCoordinates(b.Location.Coordinates.Latitude, b.Location.Coordinates.Longitude)
+}
@@ -0,0 +1,218 @@
+package dto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewBrasilapiV2(t *testing.T) {
+	type args struct {
+		cep          string
+		state        string
+		city         string
+		neighborhood string
+		street       string
+		service      string
+		latitude     string
+		longitude    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *BrasilapiV2
+		wantErr bool
+	}{
+		{
+			name: "new brasilapi v2",
+			args: args{
+				cep:          "39408078",
+				state:        "MG",
+				city:         "Montes Claros",
+				neighborhood: "Ibituruna",
+				street:       "Avenida Herlindo Silveira",
+				service:      "open-cep",
+				latitude:     "-16.7350934",
+				longitude:    "-43.8772265",
+			},
+			want: &BrasilapiV2{
+				Brasilapi: Brasilapi{
+					Cep:          "39408078",
+					State:        "MG",
+					City:         "Montes Claros",
+					Neighborhood: "Ibituruna",
+					Street:       "Avenida Herlindo Silveira",
+					Service:      "open-cep",
+				},
+				Location: BrasilapiV2Location{
+					Type: "Point",
+					Coordinates: BrasilapiV2Coordinates{
+						Longitude: "-43.8772265",
+						Latitude:  "-16.7350934",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "new brasilapi v2 error",
+			args: args{
+				cep:          "39408078",
+				state:        "MG",
+				city:         "Montes Claros",
+				neighborhood: "Ibituruna",
+				street:       "Avenida Herlindo Silveira",
+				service:      "open-cep",
+				latitude:     "-16.7350934",
+				longitude:    "",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBrasilapiV2(tt.args.cep, tt.args.state, tt.args.city, tt.args.neighborhood, tt.args.street, tt.args.service, tt.args.latitude, tt.args.longitude)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBrasilapiV2() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewBrasilapiV2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBrasilapiV2FromJson(t *testing.T) {
+	tests := []struct {
+		name       string
+		jsonString string
+		wantLat    float64
+		wantLon    float64
+		wantCoords bool
+		wantErr    bool
+	}{
+		{
+			name:       "new brasilapi v2 from json",
+			jsonString: `{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{"longitude":"-43.8772265","latitude":"-16.7350934"}}}`,
+			wantLat:    -16.7350934,
+			wantLon:    -43.8772265,
+			wantCoords: true,
+			wantErr:    false,
+		},
+		{
+			name:       "new brasilapi v2 from json with numeric coordinates",
+			jsonString: `{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{"longitude":-43.8772265,"latitude":-16.7350934}}}`,
+			wantLat:    -16.7350934,
+			wantLon:    -43.8772265,
+			wantCoords: true,
+			wantErr:    false,
+		},
+		{
+			name:       "new brasilapi v2 from json without coordinates",
+			jsonString: `{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{}}}`,
+			wantCoords: false,
+			wantErr:    false,
+		},
+		{
+			name:       "new brasilapi v2 from json with coordinates outside brazil",
+			jsonString: `{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{"longitude":"43.8772265","latitude":"16.7350934"}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "new brasilapi v2 from json with invalid coordinate",
+			jsonString: `{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{"longitude":"west","latitude":"-16.7350934"}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "new brasilapi v2 from json with invalid address",
+			jsonString: `{"cep":"3940807","state":"MG","city":"Montes Claros","neighborhood":"Ibituruna","street":"Avenida Herlindo Silveira","service":"open-cep","location":{"type":"Point","coordinates":{}}}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBrasilapiV2FromJson(tt.jsonString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewBrasilapiV2FromJson() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			lat, lon, ok := got.Coordinates()
+			if ok != tt.wantCoords || lat != tt.wantLat || lon != tt.wantLon {
+				t.Errorf("BrasilapiV2.Coordinates() = %v, %v, %v, want %v, %v, %v", lat, lon, ok, tt.wantLat, tt.wantLon, tt.wantCoords)
+			}
+		})
+	}
+}
+
+func TestBrasilapiV2_Validate(t *testing.T) {
+	address := Brasilapi{
+		Cep:          "39408078",
+		State:        "MG",
+		City:         "Montes Claros",
+		Neighborhood: "Ibituruna",
+		Street:       "Avenida Herlindo Silveira",
+		Service:      "open-cep",
+	}
+	tests := []struct {
+		name    string
+		b       *BrasilapiV2
+		wantErr bool
+	}{
+		{
+			name: "brasilapi v2 validate",
+			b: &BrasilapiV2{
+				Brasilapi: address,
+				Location: BrasilapiV2Location{
+					Type:        "Point",
+					Coordinates: BrasilapiV2Coordinates{Longitude: "-43.8772265", Latitude: "-16.7350934"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "brasilapi v2 validate without location",
+			b:       &BrasilapiV2{Brasilapi: address},
+			wantErr: false,
+		},
+		{
+			name: "brasilapi v2 invalid location type",
+			b: &BrasilapiV2{
+				Brasilapi: address,
+				Location: BrasilapiV2Location{
+					Type:        "Polygon",
+					Coordinates: BrasilapiV2Coordinates{Longitude: "-43.8772265", Latitude: "-16.7350934"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "brasilapi v2 latitude out of range",
+			b: &BrasilapiV2{
+				Brasilapi: address,
+				Location: BrasilapiV2Location{
+					Type:        "Point",
+					Coordinates: BrasilapiV2Coordinates{Longitude: "-43.8772265", Latitude: "-46.7350934"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "brasilapi v2 invalid address",
+			b: &BrasilapiV2{
+				Brasilapi: Brasilapi{Cep: "39408078", State: "MG", Service: "open-cep"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.b.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("BrasilapiV2.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"strings"
 
 	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
 )
@@ -14,6 +15,14 @@ type Cep struct {
 	City         string `json:"city"`
 	Neighborhood string `json:"neighborhood"`
 	Street       string `json:"street"`
+	// StreetType and StreetName are the parts of Street set by ParseStreet, such
+	// as "Avenida" and "Herlindo Silveira".
+	StreetType   string   `json:"street_type,omitempty"`
+	StreetName   string   `json:"street_name,omitempty"`
+	CityLevel    bool     `json:"city_level,omitempty"`
+	Inconsistent bool     `json:"inconsistent,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
 }
 
 // NewCep creates a new Cep instance with the provided details and validates it.
@@ -26,6 +35,7 @@ func NewCep(cep, state, city, neighborhood, street string) (*Cep, error) {
 		Neighborhood: neighborhood,
 		Street:       street,
 	}
+	c.CityLevel = c.IsCityLevel()
 	if err := c.Validate(); err != nil {
 		return nil, err
 	}
@@ -45,30 +55,77 @@ func (c *Cep) ToJson() (string, error) {
 }
 
 // Validate validates the Cep fields and returns an error if any of them are invalid.
-// It checks if the cep is valid, uf is a valid short state name,
-// city, neighborhood and street are not empty.
+// It checks if the cep is valid and belongs to some state range, uf is a valid short state name, city is not empty
+// and neighborhood and street are either both filled or, for a city-level cep, both empty.
 func (c *Cep) Validate() error {
-	if _, err := shared.ValidateCep(c.Cep); err != nil {
+	if _, err := shared.ValidateCepRange(c.Cep); err != nil {
 		return err
 	}
 	if !shared.ValidateStateShort(c.State) {
 		return errors.New("state not found")
 	}
-	if c.City == "" || c.Neighborhood == "" || c.Street == "" {
-		return errors.New("city, neighborhood and street must not be empty")
-	}
-	return nil
+	return validateLocality(c.City, c.Neighborhood, c.Street)
+}
+
+// IsCityLevel reports whether the Cep covers a whole city, that is,
+// it has no neighborhood and no street.
+func (c *Cep) IsCityLevel() bool {
+	return isCityLevel(c.Neighborhood, c.Street)
+}
+
+// ParseStreet sets StreetType and StreetName from Street, with the type written in
+// full, so "R. das Flores" gives "Rua" and "das Flores". A street without a known
+// type leaves StreetType empty and StreetName with the whole street.
+func (c *Cep) ParseStreet() {
+	st, name, _ := shared.ParseStreet(c.Street)
+	c.StreetType, c.StreetName = st.Name, name
+}
+
+// SameAddress reports whether c and other, as answered by two providers, are the
+// same address: the same cep, with or without the dash, the same state, and a city,
+// neighborhood and street equal as shared.EqualAddress tells, so "S. Paulo" is
+// "São Paulo".
+func (c *Cep) SameAddress(other *Cep) bool {
+	return strings.ReplaceAll(c.Cep, "-", "") == strings.ReplaceAll(other.Cep, "-", "") &&
+		strings.EqualFold(c.State, other.State) &&
+		shared.EqualAddress(c.City, other.City) &&
+		shared.EqualAddress(c.Neighborhood, other.Neighborhood) &&
+		shared.EqualAddress(c.Street, other.Street)
+}
+
+// Similarity returns the mean shared.Similarity of the city, the neighborhood and
+// the street of c and other, from 0 to 1.
+func (c *Cep) Similarity(other *Cep) float64 {
+	return (shared.Similarity(c.City, other.City) +
+		shared.Similarity(c.Neighborhood, other.Neighborhood) +
+		shared.Similarity(c.Street, other.Street)) / 3
+}
+
+// MatchesCepRange reports whether the state of the Cep is the state that owns the cep range.
+// A mismatch means the provider returned inconsistent data.
+func (c *Cep) MatchesCepRange() bool {
+	ok, _ := shared.ValidateCepState(c.Cep, c.State)
+	return ok
 }
 
 // LogValue returns a slog.Value representing the Cep instance.
-// It includes fields such as cep, street, neighborhood, city, and state
-// in a grouped format for logging purposes.
+// It includes fields such as cep, street, neighborhood, city, and state,
+// plus latitude and longitude when known, in a grouped format for logging purposes.
 func (c *Cep) LogValue() slog.Value {
-	return slog.GroupValue(
+	attrs := []slog.Attr{
 		slog.String("cep", c.Cep),
 		slog.String("street", c.Street),
 		slog.String("neighborhood", c.Neighborhood),
 		slog.String("city", c.City),
 		slog.String("state", c.State),
-	)
+		slog.Bool("city_level", c.CityLevel),
+		slog.Bool("inconsistent", c.Inconsistent),
+	}
+	if c.Latitude != nil && c.Longitude != nil {
+		attrs = append(attrs,
+			slog.Float64("latitude", *c.Latitude),
+			slog.Float64("longitude", *c.Longitude),
+		)
+	}
+	return slog.GroupValue(attrs...)
 }
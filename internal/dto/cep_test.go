@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -55,6 +56,23 @@ func TestNewCep(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "new cep city level",
+			args: args{
+				cep:          "39270000",
+				state:        "MG",
+				city:         "Lassance",
+				neighborhood: "",
+				street:       "",
+			},
+			want: &Cep{
+				Cep:       "39270000",
+				State:     "MG",
+				City:      "Lassance",
+				CityLevel: true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "new cep error",
 			args: args{
@@ -101,6 +119,17 @@ func TestCep_ToJson(t *testing.T) {
 			want:    "{\"cep\":\"39408078\",\"state\":\"MG\",\"city\":\"Montes Claros\",\"neighborhood\":\"Ibituruna\",\"street\":\"Avenida Herlindo Silveira\"}",
 			wantErr: false,
 		},
+		{
+			name: "to json city level",
+			c: &Cep{
+				Cep:       "39270000",
+				State:     "MG",
+				City:      "Lassance",
+				CityLevel: true,
+			},
+			want:    "{\"cep\":\"39270000\",\"state\":\"MG\",\"city\":\"Lassance\",\"neighborhood\":\"\",\"street\":\"\",\"city_level\":true}",
+			wantErr: false,
+		},
 		{
 			name:    "to json error",
 			c:       &Cep{},
@@ -214,6 +243,34 @@ func TestCep_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "validate cep out of any state range",
+			c: &Cep{
+				Cep:          "00000000",
+				State:        "SP",
+				City:         "São Paulo",
+				Neighborhood: "Sé",
+				Street:       "Praça da Sé",
+			},
+			wantErr: true,
+		},
+		{
+			name: "validate city level cep",
+			c: &Cep{
+				Cep:   "39270000",
+				State: "MG",
+				City:  "Lassance",
+			},
+			wantErr: false,
+		},
+		{
+			name: "validate city level cep without city",
+			c: &Cep{
+				Cep:   "39270000",
+				State: "MG",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -223,3 +280,139 @@ func TestCep_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestCep_IsCityLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Cep
+		want bool
+	}{
+		{
+			name: "street level cep",
+			c: &Cep{
+				Cep:          "39408078",
+				State:        "MG",
+				City:         "Montes Claros",
+				Neighborhood: "Ibituruna",
+				Street:       "Avenida Herlindo Silveira",
+			},
+			want: false,
+		},
+		{
+			name: "city level cep",
+			c: &Cep{
+				Cep:   "39270000",
+				State: "MG",
+				City:  "Lassance",
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsCityLevel(); got != tt.want {
+				t.Errorf("Cep.IsCityLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCep_ParseStreet(t *testing.T) {
+	tests := []struct {
+		name     string
+		street   string
+		wantType string
+		wantName string
+	}{
+		{name: "full type", street: "Avenida Herlindo Silveira", wantType: "Avenida", wantName: "Herlindo Silveira"},
+		{name: "abbreviated type", street: "R. das Flores", wantType: "Rua", wantName: "das Flores"},
+		{name: "unknown type", street: "Via Anchieta", wantName: "Via Anchieta"},
+		{name: "city level", street: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cep{Street: tt.street}
+			c.ParseStreet()
+			if c.StreetType != tt.wantType || c.StreetName != tt.wantName {
+				t.Errorf("Cep.ParseStreet() = %q, %q, want %q, %q", c.StreetType, c.StreetName, tt.wantType, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestCep_SameAddress(t *testing.T) {
+	brasilapi := &Cep{Cep: "01001000", State: "SP", City: "São Paulo", Neighborhood: "Sé", Street: "Praça da Sé"}
+	tests := []struct {
+		name           string
+		other          *Cep
+		want           bool
+		wantSimilarity float64
+	}{
+		{
+			name:           "same address written differently",
+			other:          &Cep{Cep: "01001-000", State: "sp", City: "S. Paulo", Neighborhood: "Se", Street: "Pça. da Sé"},
+			want:           true,
+			wantSimilarity: 1,
+		},
+		{
+			name:           "other street",
+			other:          &Cep{Cep: "01001000", State: "SP", City: "São Paulo", Neighborhood: "Sé", Street: "Praça da Paz"},
+			want:           false,
+			wantSimilarity: (1 + 1 + 0.75) / 3,
+		},
+		{
+			name:           "other cep",
+			other:          &Cep{Cep: "01001001", State: "SP", City: "São Paulo", Neighborhood: "Sé", Street: "Praça da Sé"},
+			want:           false,
+			wantSimilarity: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := brasilapi.SameAddress(tt.other); got != tt.want {
+				t.Errorf("Cep.SameAddress() = %v, want %v", got, tt.want)
+			}
+			if got := brasilapi.Similarity(tt.other); math.Abs(got-tt.wantSimilarity) > 0.001 {
+				t.Errorf("Cep.Similarity() = %.3f, want %.3f", got, tt.wantSimilarity)
+			}
+		})
+	}
+}
+
+func TestCep_MatchesCepRange(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Cep
+		want bool
+	}{
+		{
+			name: "state matches cep range",
+			c: &Cep{
+				Cep:          "39408078",
+				State:        "MG",
+				City:         "Montes Claros",
+				Neighborhood: "Ibituruna",
+				Street:       "Avenida Herlindo Silveira",
+			},
+			want: true,
+		},
+		{
+			name: "state does not match cep range",
+			c: &Cep{
+				Cep:          "39408078",
+				State:        "SP",
+				City:         "Montes Claros",
+				Neighborhood: "Ibituruna",
+				Street:       "Avenida Herlindo Silveira",
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.MatchesCepRange(); got != tt.want {
+				t.Errorf("Cep.MatchesCepRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
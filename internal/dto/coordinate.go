@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Bounding box that contains the Brazilian territory, including the oceanic islands.
+const (
+	minLatitude  = -34.0
+	maxLatitude  = 5.5
+	minLongitude = -74.0
+	maxLongitude = -28.5
+)
+
+// Coordinate is a latitude or longitude as returned by the providers.
+// Brasilapi v2 and AwesomeAPI send it as a string, but a number is accepted too.
+type Coordinate string
+
+// UnmarshalJSON accepts a JSON string, a JSON number or null.
+func (c *Coordinate) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		*c = ""
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		*c = Coordinate(strings.TrimSpace(str))
+		return nil
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return errors.New("coordinate must be a number or a string")
+	}
+	*c = Coordinate(s)
+	return nil
+}
+
+// validateCoordinates checks a latitude and longitude pair. Both may be empty, since
+// providers do not know the coordinates of every cep. Otherwise both must be filled
+// and be numbers inside the Brazilian territory.
+func validateCoordinates(latitude, longitude Coordinate) error {
+	if latitude == "" && longitude == "" {
+		return nil
+	}
+	if latitude == "" || longitude == "" {
+		return errors.New("latitude and longitude must be both filled or both empty")
+	}
+	lat, err := strconv.ParseFloat(string(latitude), 64)
+	if err != nil {
+		return errors.New("latitude is not a number")
+	}
+	lon, err := strconv.ParseFloat(string(longitude), 64)
+	if err != nil {
+		return errors.New("longitude is not a number")
+	}
+	if lat < minLatitude || lat > maxLatitude {
+		return errors.New("latitude out of the Brazilian territory")
+	}
+	if lon < minLongitude || lon > maxLongitude {
+		return errors.New("longitude out of the Brazilian territory")
+	}
+	return nil
+}
+
+// parseCoordinates returns latitude and longitude as numbers.
+// ok is false when any of them is missing or is not a number.
+func parseCoordinates(latitude, longitude Coordinate) (lat, lon float64, ok bool) {
+	lat, err := strconv.ParseFloat(string(latitude), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err = strconv.ParseFloat(string(longitude), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
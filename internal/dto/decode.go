@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ErrNotFound is returned when a provider answers that the cep does not exist.
+var ErrNotFound = errors.New("not found")
+
+var utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+// DecodeJson unmarshals a provider response body into v, tolerating what
+// providers actually send: a leading UTF-8 byte order mark, surrounding
+// whitespace, bodies encoded in ISO-8859-1/Windows-1252 instead of UTF-8,
+// and fields the DTO does not know about, which are ignored.
+func DecodeJson(body []byte, v any) error {
+	body = bytes.TrimSpace(bytes.TrimPrefix(body, utf8Bom))
+	if len(body) == 0 {
+		return errors.New("empty body")
+	}
+	if !utf8.Valid(body) {
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(body)
+		if err != nil {
+			return errors.New("body is neither UTF-8 nor Windows-1252: " + err.Error())
+		}
+		body = decoded
+	}
+	return json.Unmarshal(body, v)
+}
+
+// FlexibleBool is a boolean that also accepts the strings "true" and "false",
+// as ViaCEP has sent its "erro" flag both as {"erro": true} and {"erro": "true"}.
+type FlexibleBool bool
+
+// UnmarshalJSON accepts a JSON boolean, a string holding "true" or "false"
+// in any case, or null, which is false.
+func (f *FlexibleBool) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		*f = false
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true":
+		*f = true
+	case "false", "":
+		*f = false
+	default:
+		return errors.New("invalid boolean: " + s)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"testing"
+)
+
+func TestDecodeJson(t *testing.T) {
+	type target struct {
+		City string `json:"city"`
+	}
+	tests := []struct {
+		name    string
+		body    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "decode json", body: []byte(`{"city":"São Paulo"}`), want: "São Paulo"},
+		{name: "decode json with byte order mark", body: append([]byte{0xEF, 0xBB, 0xBF}, `{"city":"São Paulo"}`...), want: "São Paulo"},
+		{name: "decode json in latin1", body: []byte("{\"city\":\"S\xe3o Paulo\"}"), want: "São Paulo"},
+		{name: "decode json with unknown fields", body: []byte(`{"city":"São Paulo","zone":"sul"}`), want: "São Paulo"},
+		{name: "decode json with surrounding whitespace", body: []byte("\n  {\"city\":\"São Paulo\"}\n"), want: "São Paulo"},
+		{name: "decode json empty body", body: []byte(""), wantErr: true},
+		{name: "decode json html body", body: []byte("<html></html>"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got target
+			err := DecodeJson(tt.body, &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecodeJson() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.City != tt.want {
+				t.Errorf("DecodeJson() = %v, want %v", got.City, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexibleBool_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    FlexibleBool
+		wantErr bool
+	}{
+		{name: "boolean true", data: `true`, want: true},
+		{name: "boolean false", data: `false`, want: false},
+		{name: "string true", data: `"true"`, want: true},
+		{name: "string True", data: `"True"`, want: true},
+		{name: "string false", data: `"false"`, want: false},
+		{name: "null", data: `null`, want: false},
+		{name: "invalid", data: `"yes"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FlexibleBool
+			err := got.UnmarshalJSON([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FlexibleBool.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("FlexibleBool.UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Codes of an ErrorDetail.
+const (
+	CodeNotFound              = "not_found"
+	CodeInvalidCep            = "invalid_cep"
+	CodeAllProvidersFailed    = "all_providers_failed"
+	CodeTimeout               = "timeout"
+	CodeCanceled              = "canceled"
+	CodeUpstream              = "upstream"
+	CodeNetwork               = "network"
+	CodeBodyTooLarge          = "body_too_large"
+	CodeUnexpectedContentType = "unexpected_content_type"
+	CodeUnknown               = "unknown"
+)
+
+// CodedError is an error with the code and the retryability it has in an
+// ErrorDetail, for the sentinel errors of the packages dto cannot see, such as
+// usecase.ErrInvalidCep.
+type CodedError struct {
+	Code      string
+	Message   string
+	Retryable bool
+}
+
+// NewCodedError returns a CodedError, to be compared with errors.Is.
+func NewCodedError(code, message string, retryable bool) *CodedError {
+	return &CodedError{Code: code, Message: message, Retryable: retryable}
+}
+
+// Error returns the message.
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// ErrorDetail is the JSON form of an error: a stable code, the message, the
+// provider that failed, the status it answered and whether trying again may
+// succeed. It is the error of a Response read back from JSON, and it matches
+// the error it was made from with errors.Is, as far as the code tells.
+type ErrorDetail struct {
+	Code       string         `json:"code"`
+	Message    string         `json:"message"`
+	Provider   string         `json:"provider,omitempty"`
+	StatusCode int            `json:"status_code,omitempty"`
+	Retryable  bool           `json:"retryable"`
+	Errors     []ServiceError `json:"errors,omitempty"`
+}
+
+// NewErrorDetail returns the detail of err, failed at provider, or nil if err is nil.
+// The code comes from the first of: an ErrorDetail or a CodedError wrapped by err,
+// a "not found", an UpstreamError, a context error or a network error. Timeouts,
+// network errors, 5xx, 408 and 429 answers are retryable.
+func NewErrorDetail(err error, provider string) *ErrorDetail {
+	if err == nil {
+		return nil
+	}
+	var detail *ErrorDetail
+	if errors.As(err, &detail) {
+		d := *detail
+		d.Message = err.Error()
+		if d.Provider == "" {
+			d.Provider = provider
+		}
+		return &d
+	}
+	d := &ErrorDetail{Code: CodeUnknown, Message: err.Error(), Provider: provider}
+	var coded *CodedError
+	var upstream *UpstreamError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &coded):
+		d.Code, d.Retryable = coded.Code, coded.Retryable
+	case errors.As(err, &upstream):
+		d.StatusCode, d.Errors = upstream.StatusCode, upstream.Errors
+		d.Retryable = retryableStatus(upstream.StatusCode)
+		switch upstream.StatusCode {
+		case http.StatusNotFound:
+			d.Code = CodeNotFound
+		case http.StatusBadRequest:
+			d.Code = CodeInvalidCep
+		default:
+			d.Code = CodeUpstream
+		}
+	case errors.Is(err, ErrNotFound):
+		d.Code = CodeNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		d.Code, d.Retryable = CodeTimeout, true
+	case errors.Is(err, context.Canceled):
+		d.Code = CodeCanceled
+	case errors.As(err, &netErr):
+		d.Code, d.Retryable = CodeNetwork, true
+	}
+	return d
+}
+
+// retryableStatus reports whether an answer with the status may succeed if asked again.
+func retryableStatus(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
+
+// Error returns the message.
+func (e *ErrorDetail) Error() string {
+	return e.Message
+}
+
+// Is reports whether the code is the one of target: ErrNotFound, a context error or
+// a CodedError.
+func (e *ErrorDetail) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == CodeNotFound
+	case context.DeadlineExceeded:
+		return e.Code == CodeTimeout
+	case context.Canceled:
+		return e.Code == CodeCanceled
+	}
+	coded, ok := target.(*CodedError)
+	return ok && e.Code == coded.Code
+}
@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+// LabelOptions tell how Cep.Label lays out an address.
+type LabelOptions struct {
+	// Number and Complement are the number and the complement of the address, such
+	// as "1200" and "apto 301", which the providers do not know.
+	Number     string
+	Complement string
+	// Uppercase writes the address in capitals, as many label printers expect.
+	Uppercase bool
+	// Abbreviate writes the street type abbreviated, as "Av." for "Avenida".
+	Abbreviate bool
+}
+
+// Label is an address laid out in the fields of a Correios label.
+type Label struct {
+	// Street is the street followed by the number and the complement, as in
+	// "Avenida Herlindo Silveira, 1200 - apto 301".
+	Street       string `json:"street,omitempty"`
+	Neighborhood string `json:"neighborhood,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	// Cep is written with the dash, as in "39408-078".
+	Cep string `json:"cep"`
+}
+
+// Label returns the address of the cep laid out for a postal label.
+// A city-level cep has no street nor neighborhood, but still gets the number
+// and the complement.
+func (c *Cep) Label(opts LabelOptions) Label {
+	street := c.Street
+	if opts.Abbreviate {
+		street = abbreviateStreet(street)
+	}
+	if opts.Number != "" {
+		street = joinNonEmpty(", ", street, opts.Number)
+	}
+	if opts.Complement != "" {
+		street = joinNonEmpty(" - ", street, opts.Complement)
+	}
+	l := Label{
+		Street:       street,
+		Neighborhood: c.Neighborhood,
+		City:         c.City,
+		State:        c.State,
+		Cep:          FormatCep(c.Cep),
+	}
+	if opts.Uppercase {
+		l.Street = strings.ToUpper(l.Street)
+		l.Neighborhood = strings.ToUpper(l.Neighborhood)
+		l.City = strings.ToUpper(l.City)
+		l.State = strings.ToUpper(l.State)
+	}
+	return l
+}
+
+// Lines returns the lines of the label in the Correios order: the street, the
+// neighborhood, "City - UF" and the cep. Empty lines are left out, and so is the
+// dash of a city without a state or a state without a city.
+func (l Label) Lines() []string {
+	var lines []string
+	for _, line := range []string{l.Street, l.Neighborhood, joinNonEmpty(" - ", l.City, l.State), l.Cep} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// String returns the lines of the label, one per line.
+func (l Label) String() string {
+	return strings.Join(l.Lines(), "\n")
+}
+
+// Line returns the label in a single line, as in
+// "Avenida Herlindo Silveira, Ibituruna, Montes Claros - MG, 39408-078".
+func (l Label) Line() string {
+	return strings.Join(l.Lines(), ", ")
+}
+
+// FormatCep returns a cep of 8 digits with the dash, as in "39408-078".
+// Any other value is returned as is.
+func FormatCep(cep string) string {
+	if ok, _ := shared.ValidateCepWithoutDash(cep); ok {
+		return cep[:5] + "-" + cep[5:]
+	}
+	return cep
+}
+
+// abbreviateStreet replaces the street type at the start of street by its
+// abbreviation, so "Avenida Herlindo Silveira" becomes "Av. Herlindo Silveira".
+func abbreviateStreet(street string) string {
+	st, name, ok := shared.ParseStreet(street)
+	if !ok {
+		return street
+	}
+	return st.Abbreviation + " " + name
+}
+
+// joinNonEmpty joins the parts that are not empty with sep.
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
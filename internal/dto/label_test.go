@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCep_Label(t *testing.T) {
+	cep := Cep{Cep: "39408078", State: "MG", City: "Montes Claros", Neighborhood: "Ibituruna", Street: "Avenida Herlindo Silveira"}
+	cityLevel := Cep{Cep: "39400000", State: "MG", City: "Montes Claros", CityLevel: true}
+	tests := []struct {
+		name      string
+		cep       Cep
+		opts      LabelOptions
+		want      Label
+		wantLines []string
+		wantLine  string
+	}{
+		{
+			name:      "plain",
+			cep:       cep,
+			want:      Label{Street: "Avenida Herlindo Silveira", Neighborhood: "Ibituruna", City: "Montes Claros", State: "MG", Cep: "39408-078"},
+			wantLines: []string{"Avenida Herlindo Silveira", "Ibituruna", "Montes Claros - MG", "39408-078"},
+			wantLine:  "Avenida Herlindo Silveira, Ibituruna, Montes Claros - MG, 39408-078",
+		},
+		{
+			name:      "number, complement, abbreviated and uppercase",
+			cep:       cep,
+			opts:      LabelOptions{Number: "1200", Complement: "apto 301", Uppercase: true, Abbreviate: true},
+			want:      Label{Street: "AV. HERLINDO SILVEIRA, 1200 - APTO 301", Neighborhood: "IBITURUNA", City: "MONTES CLAROS", State: "MG", Cep: "39408-078"},
+			wantLines: []string{"AV. HERLINDO SILVEIRA, 1200 - APTO 301", "IBITURUNA", "MONTES CLAROS - MG", "39408-078"},
+			wantLine:  "AV. HERLINDO SILVEIRA, 1200 - APTO 301, IBITURUNA, MONTES CLAROS - MG, 39408-078",
+		},
+		{
+			name:      "abbreviated square with complement",
+			cep:       Cep{Cep: "01001000", State: "SP", City: "São Paulo", Neighborhood: "Sé", Street: "Praça da Sé"},
+			opts:      LabelOptions{Abbreviate: true, Complement: "lado ímpar"},
+			want:      Label{Street: "Pç. da Sé - lado ímpar", Neighborhood: "Sé", City: "São Paulo", State: "SP", Cep: "01001-000"},
+			wantLines: []string{"Pç. da Sé - lado ímpar", "Sé", "São Paulo - SP", "01001-000"},
+			wantLine:  "Pç. da Sé - lado ímpar, Sé, São Paulo - SP, 01001-000",
+		},
+		{
+			name:      "city level with number",
+			cep:       cityLevel,
+			opts:      LabelOptions{Number: "s/n"},
+			want:      Label{Street: "s/n", City: "Montes Claros", State: "MG", Cep: "39400-000"},
+			wantLines: []string{"s/n", "Montes Claros - MG", "39400-000"},
+			wantLine:  "s/n, Montes Claros - MG, 39400-000",
+		},
+		{
+			name:      "without state",
+			cep:       Cep{Cep: "39400000", City: "Montes Claros"},
+			want:      Label{City: "Montes Claros", Cep: "39400-000"},
+			wantLines: []string{"Montes Claros", "39400-000"},
+			wantLine:  "Montes Claros, 39400-000",
+		},
+		{
+			name:      "without city nor state",
+			cep:       Cep{Cep: "39400000"},
+			want:      Label{Cep: "39400-000"},
+			wantLines: []string{"39400-000"},
+			wantLine:  "39400-000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cep.Label(tt.opts)
+			if got != tt.want {
+				t.Errorf("Label() = %+v, want %+v", got, tt.want)
+			}
+			if lines := got.Lines(); !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("Lines() = %q, want %q", lines, tt.wantLines)
+			}
+			if line := got.Line(); line != tt.wantLine {
+				t.Errorf("Line() = %q, want %q", line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestFormatCep(t *testing.T) {
+	tests := []struct {
+		cep  string
+		want string
+	}{
+		{cep: "39408078", want: "39408-078"},
+		{cep: "39408-078", want: "39408-078"},
+		{cep: "394080", want: "394080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cep, func(t *testing.T) {
+			if got := FormatCep(tt.cep); got != tt.want {
+				t.Errorf("FormatCep() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
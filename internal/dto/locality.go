@@ -0,0 +1,25 @@
+package dto
+
+import "errors"
+
+// isCityLevel reports whether neighborhood and street are both empty,
+// which is how the providers describe a cep that covers a whole city.
+func isCityLevel(neighborhood, street string) bool {
+	return neighborhood == "" && street == ""
+}
+
+// validateLocality checks the locality fields shared by every cep representation.
+// The city must not be empty. Neighborhood and street must be both filled or,
+// for a city-level cep, both empty.
+func validateLocality(city, neighborhood, street string) error {
+	if city == "" {
+		return errors.New("city must not be empty")
+	}
+	if isCityLevel(neighborhood, street) {
+		return nil
+	}
+	if neighborhood == "" || street == "" {
+		return errors.New("neighborhood and street must not be empty unless the cep is city-level")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+type Opencep struct {
+	Cep         string `json:"cep"`
+	Logradouro  string `json:"logradouro"`
+	Complemento string `json:"complemento"`
+	Unidade     string `json:"unidade"`
+	Bairro      string `json:"bairro"`
+	Localidade  string `json:"localidade"`
+	Uf          string `json:"uf"`
+	Ibge        string `json:"ibge"`
+}
+
+// NewOpencep creates a new Opencep instance and validates it.
+// It returns an error if the validation fails.
+func NewOpencep(cep, logradouro, complemento, unidade, bairro, localidade, uf, ibge string) (*Opencep, error) {
+	o := &Opencep{
+		Cep:         cep,
+		Logradouro:  logradouro,
+		Complemento: complemento,
+		Unidade:     unidade,
+		Bairro:      bairro,
+		Localidade:  localidade,
+		Uf:          uf,
+		Ibge:        ibge,
+	}
+	err := o.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// NewOpencepFromJson creates a new Opencep instance from a JSON string and validates it.
+// It returns the created Opencep instance or an error if the JSON is invalid or validation fails.
+func NewOpencepFromJson(jsonString string) (*Opencep, error) {
+	var o Opencep
+	err := DecodeJson([]byte(jsonString), &o)
+	if err != nil {
+		return nil, err
+	}
+	err = o.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Validate validates the Opencep fields and returns an error if any of them are invalid.
+// OpenCEP answers in the ViaCEP format without estado and regiao, so it checks that the
+// cep is valid, with '-', and belongs to some state range, uf is a valid short state name,
+// the ibge code, when present, belongs to that uf, localidade is not empty and bairro and
+// logradouro are either both filled or both empty.
+func (o *Opencep) Validate() error {
+	if _, err := shared.ValidateCepWithDash(o.Cep); err != nil {
+		return err
+	}
+	if _, err := shared.ValidateCepRange(o.Cep); err != nil {
+		return err
+	}
+	state, ok := shared.StateByUf(o.Uf)
+	if !ok {
+		return errors.New("uf not found")
+	}
+	if o.Ibge != "" && !strings.HasPrefix(o.Ibge, state.IbgeCode) {
+		return errors.New("ibge does not belong to uf")
+	}
+	return validateLocality(o.Localidade, o.Bairro, o.Logradouro)
+}
+
+// IsCityLevel reports whether the Opencep record covers a whole city,
+// that is, it has no bairro and no logradouro.
+func (o *Opencep) IsCityLevel() bool {
+	return isCityLevel(o.Bairro, o.Logradouro)
+}
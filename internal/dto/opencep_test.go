@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewOpencep(t *testing.T) {
+	tests := []struct {
+		name    string
+		cep     string
+		want    *Opencep
+		wantErr bool
+	}{
+		{
+			name: "new opencep",
+			cep:  "39408-078",
+			want: &Opencep{
+				Cep:         "39408-078",
+				Logradouro:  "Avenida Herlindo Silveira",
+				Complemento: "até 499/500",
+				Bairro:      "Ibituruna",
+				Localidade:  "Montes Claros",
+				Uf:          "MG",
+				Ibge:        "3143302",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "new opencep error",
+			cep:     "39408078",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewOpencep(tt.cep, "Avenida Herlindo Silveira", "até 499/500", "", "Ibituruna", "Montes Claros", "MG", "3143302")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewOpencep() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewOpencep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOpencepFromJson(t *testing.T) {
+	b, err := os.ReadFile("../../responses/opencep.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewOpencepFromJson(string(b))
+	if err != nil {
+		t.Fatalf("NewOpencepFromJson() error = %v", err)
+	}
+	want := &Opencep{
+		Cep:         "39408-078",
+		Logradouro:  "Avenida Herlindo Silveira",
+		Complemento: "até 499/500",
+		Bairro:      "Ibituruna",
+		Localidade:  "Montes Claros",
+		Uf:          "MG",
+		Ibge:        "3143302",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewOpencepFromJson() = %v, want %v", got, want)
+	}
+}
+
+func TestOpencep_Validate(t *testing.T) {
+	valid := Opencep{
+		Cep:        "39408-078",
+		Logradouro: "Avenida Herlindo Silveira",
+		Bairro:     "Ibituruna",
+		Localidade: "Montes Claros",
+		Uf:         "MG",
+		Ibge:       "3143302",
+	}
+	tests := []struct {
+		name    string
+		change  func(o *Opencep)
+		wantErr bool
+	}{
+		{name: "opencep validate", change: func(o *Opencep) {}, wantErr: false},
+		{name: "opencep city level", change: func(o *Opencep) { o.Logradouro, o.Bairro = "", "" }, wantErr: false},
+		{name: "opencep cep out of range", change: func(o *Opencep) { o.Cep = "00000-000" }, wantErr: true},
+		{name: "opencep invalid uf", change: func(o *Opencep) { o.Uf = "MM" }, wantErr: true},
+		{name: "opencep ibge of another uf", change: func(o *Opencep) { o.Ibge = "3550308" }, wantErr: true},
+		{name: "opencep invalid localidade", change: func(o *Opencep) { o.Localidade = "" }, wantErr: true},
+		{name: "opencep invalid logradouro", change: func(o *Opencep) { o.Logradouro = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid
+			tt.change(&o)
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Opencep.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+type PostmonEstadoInfo struct {
+	AreaKm2    string `json:"area_km2"`
+	CodigoIbge string `json:"codigo_ibge"`
+	Nome       string `json:"nome"`
+}
+
+type PostmonCidadeInfo struct {
+	AreaKm2    string `json:"area_km2"`
+	CodigoIbge string `json:"codigo_ibge"`
+}
+
+type Postmon struct {
+	Bairro     string            `json:"bairro"`
+	Cidade     string            `json:"cidade"`
+	Logradouro string            `json:"logradouro"`
+	EstadoInfo PostmonEstadoInfo `json:"estado_info"`
+	Cep        string            `json:"cep"`
+	CidadeInfo PostmonCidadeInfo `json:"cidade_info"`
+	Estado     string            `json:"estado"`
+}
+
+// NewPostmon creates a new Postmon instance and validates it.
+// It returns an error if the validation fails.
+//
+// estado is the short state name; the state and city info blocks are left empty.
+func NewPostmon(cep, estado, cidade, bairro, logradouro string) (*Postmon, error) {
+	p := &Postmon{
+		Bairro:     bairro,
+		Cidade:     cidade,
+		Logradouro: logradouro,
+		Cep:        cep,
+		Estado:     estado,
+	}
+	err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewPostmonFromJson creates a new Postmon instance from a JSON string and validates it.
+// It returns the created Postmon instance or an error if the JSON is invalid or validation fails.
+func NewPostmonFromJson(jsonString string) (*Postmon, error) {
+	var p Postmon
+	err := DecodeJson([]byte(jsonString), &p)
+	if err != nil {
+		return nil, err
+	}
+	err = p.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate validates the Postmon fields and returns an error if any of them are invalid.
+// It checks if the cep is valid and belongs to some state range, estado is a valid short
+// state name, the name and ibge code in estado_info, when present, are those of estado,
+// cidade is not empty and bairro and logradouro are either both filled or both empty.
+func (p *Postmon) Validate() error {
+	if _, err := shared.ValidateCepWithoutDash(p.Cep); err != nil {
+		return err
+	}
+	if _, err := shared.ValidateCepRange(p.Cep); err != nil {
+		return err
+	}
+	state, ok := shared.StateByUf(p.Estado)
+	if !ok {
+		return errors.New("estado not found")
+	}
+	if p.EstadoInfo.Nome != "" {
+		if s, ok := shared.StateByName(p.EstadoInfo.Nome); !ok || s.Uf != state.Uf {
+			return errors.New("estado_info nome does not match estado")
+		}
+	}
+	if p.EstadoInfo.CodigoIbge != "" && p.EstadoInfo.CodigoIbge != state.IbgeCode {
+		return errors.New("estado_info codigo_ibge does not match estado")
+	}
+	return validateLocality(p.Cidade, p.Bairro, p.Logradouro)
+}
+
+// IsCityLevel reports whether the Postmon record covers a whole city,
+// that is, it has no bairro and no logradouro.
+func (p *Postmon) IsCityLevel() bool {
+	return isCityLevel(p.Bairro, p.Logradouro)
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewPostmon(t *testing.T) {
+	got, err := NewPostmon("39408078", "MG", "Montes Claros", "Ibituruna", "Avenida Herlindo Silveira")
+	if err != nil {
+		t.Fatalf("NewPostmon() error = %v", err)
+	}
+	want := &Postmon{
+		Bairro:     "Ibituruna",
+		Cidade:     "Montes Claros",
+		Logradouro: "Avenida Herlindo Silveira",
+		Cep:        "39408078",
+		Estado:     "MG",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPostmon() = %v, want %v", got, want)
+	}
+	if _, err := NewPostmon("39408078", "SP", "", "", ""); err == nil {
+		t.Errorf("NewPostmon() error = nil, want error for empty cidade")
+	}
+}
+
+func TestNewPostmonFromJson(t *testing.T) {
+	b, err := os.ReadFile("../../responses/postmon.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewPostmonFromJson(string(b))
+	if err != nil {
+		t.Fatalf("NewPostmonFromJson() error = %v", err)
+	}
+	want := &Postmon{
+		Bairro:     "Ibituruna",
+		Cidade:     "Montes Claros",
+		Logradouro: "Avenida Herlindo Silveira",
+		EstadoInfo: PostmonEstadoInfo{AreaKm2: "586.521,123", CodigoIbge: "31", Nome: "Minas Gerais"},
+		Cep:        "39408078",
+		CidadeInfo: PostmonCidadeInfo{AreaKm2: "3589,811", CodigoIbge: "3143302"},
+		Estado:     "MG",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPostmonFromJson() = %v, want %v", got, want)
+	}
+}
+
+func TestPostmon_Validate(t *testing.T) {
+	valid := Postmon{
+		Bairro:     "Ibituruna",
+		Cidade:     "Montes Claros",
+		Logradouro: "Avenida Herlindo Silveira",
+		EstadoInfo: PostmonEstadoInfo{CodigoIbge: "31", Nome: "Minas Gerais"},
+		Cep:        "39408078",
+		Estado:     "MG",
+	}
+	tests := []struct {
+		name    string
+		change  func(p *Postmon)
+		wantErr bool
+	}{
+		{name: "postmon validate", change: func(p *Postmon) {}, wantErr: false},
+		{name: "postmon without estado info", change: func(p *Postmon) { p.EstadoInfo = PostmonEstadoInfo{} }, wantErr: false},
+		{name: "postmon city level", change: func(p *Postmon) { p.Bairro, p.Logradouro = "", "" }, wantErr: false},
+		{name: "postmon invalid cep", change: func(p *Postmon) { p.Cep = "39408-078" }, wantErr: true},
+		{name: "postmon invalid estado", change: func(p *Postmon) { p.Estado = "MM" }, wantErr: true},
+		{name: "postmon estado info nome mismatch", change: func(p *Postmon) { p.EstadoInfo.Nome = "São Paulo" }, wantErr: true},
+		{name: "postmon estado info codigo ibge mismatch", change: func(p *Postmon) { p.EstadoInfo.CodigoIbge = "35" }, wantErr: true},
+		{name: "postmon invalid bairro", change: func(p *Postmon) { p.Bairro = "" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.change(&p)
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Postmon.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
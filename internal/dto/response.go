@@ -1,8 +1,12 @@
 package dto
 
+import "encoding/json"
+
 type Response struct {
 	Cep   Cep   `json:"cep"`
 	Error error `json:"error"`
+	// Provider is the name of the provider that answered, when known.
+	Provider string `json:"provider,omitempty"`
 }
 
 // NewResponse creates a new Response object.
@@ -14,4 +18,39 @@ func NewResponse(cep Cep, err error) Response {
 		Cep:   cep,
 		Error: err,
 	}
-}
\ No newline at end of file
+}
+
+// responseJson is the JSON form of a Response: the cep, for a success, or the
+// ErrorDetail of the error.
+type responseJson struct {
+	Cep      *Cep         `json:"cep,omitempty"`
+	Provider string       `json:"provider,omitempty"`
+	Error    *ErrorDetail `json:"error,omitempty"`
+}
+
+// MarshalJSON writes the cep of a success or the ErrorDetail of a failure, so the
+// code, the message and the retryability of the error are kept.
+func (r Response) MarshalJSON() ([]byte, error) {
+	out := responseJson{Provider: r.Provider, Error: NewErrorDetail(r.Error, r.Provider)}
+	if r.Error == nil {
+		out.Cep = &r.Cep
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON reads a Response written by MarshalJSON. The error, if any, is an
+// *ErrorDetail.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	var in responseJson
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = Response{Provider: in.Provider}
+	if in.Cep != nil {
+		r.Cep = *in.Cep
+	}
+	if in.Error != nil {
+		r.Error = in.Error
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package dto
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -59,3 +62,81 @@ func TestNewResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestResponse_JSON(t *testing.T) {
+	invalidCep := NewCodedError(CodeInvalidCep, "invalid cep", false)
+	tests := []struct {
+		name     string
+		response Response
+		want     string
+		is       error
+	}{
+		{
+			name:     "success",
+			response: Response{Cep: Cep{Cep: "39408078", State: "MG", City: "Montes Claros"}, Provider: "viacep"},
+			want:     `{"cep":{"cep":"39408078","state":"MG","city":"Montes Claros","neighborhood":"","street":""},"provider":"viacep"}`,
+		},
+		{
+			name:     "upstream not found",
+			response: Response{Error: &UpstreamError{Service: "Brasilapi", StatusCode: 404, Message: "CEP não encontrado"}, Provider: "brasilapi"},
+			want:     `{"provider":"brasilapi","error":{"code":"not_found","message":"Brasilapi: CEP não encontrado (status 404)","provider":"brasilapi","status_code":404,"retryable":false}}`,
+			is:       ErrNotFound,
+		},
+		{
+			name:     "upstream unavailable",
+			response: Response{Error: fmt.Errorf("query: %w", &UpstreamError{Service: "Viacep", StatusCode: 503, Message: "unavailable"}), Provider: "viacep"},
+			want:     `{"provider":"viacep","error":{"code":"upstream","message":"query: Viacep: unavailable (status 503)","provider":"viacep","status_code":503,"retryable":true}}`,
+		},
+		{
+			name:     "timeout",
+			response: Response{Error: context.DeadlineExceeded},
+			want:     `{"error":{"code":"timeout","message":"context deadline exceeded","retryable":true}}`,
+			is:       context.DeadlineExceeded,
+		},
+		{
+			name:     "coded error",
+			response: Response{Error: fmt.Errorf("%w: too short", invalidCep)},
+			want:     `{"error":{"code":"invalid_cep","message":"invalid cep: too short","retryable":false}}`,
+			is:       invalidCep,
+		},
+		{
+			name:     "unknown error",
+			response: Response{Error: errors.New("boom")},
+			want:     `{"error":{"code":"unknown","message":"boom","retryable":false}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+			var got Response
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Cep != tt.response.Cep || got.Provider != tt.response.Provider {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.response)
+			}
+			if (got.Error == nil) != (tt.response.Error == nil) {
+				t.Fatalf("Unmarshal() error = %v, want %v", got.Error, tt.response.Error)
+			}
+			if got.Error != nil && got.Error.Error() != tt.response.Error.Error() {
+				t.Errorf("Unmarshal() error = %q, want %q", got.Error, tt.response.Error)
+			}
+			if tt.is != nil && !errors.Is(got.Error, tt.is) {
+				t.Errorf("errors.Is(%v, %v) = false after the round trip", got.Error, tt.is)
+			}
+			again, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != tt.want {
+				t.Errorf("Marshal() after Unmarshal() = %s, want %s", again, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServiceError is the failure reported by one of the sub-services an upstream
+// provider relies on, such as correios or widenet behind Brasilapi.
+type ServiceError struct {
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+	Service string `json:"service"`
+}
+
+// UpstreamError is the error returned when a provider answers with a non-200 status.
+// It carries the message sent by the provider and, when available, the list of
+// sub-services that failed, so the failure can be diagnosed.
+type UpstreamError struct {
+	Service    string         `json:"service"`
+	StatusCode int            `json:"status_code"`
+	Message    string         `json:"message"`
+	Type       string         `json:"type,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Errors     []ServiceError `json:"errors,omitempty"`
+}
+
+// Error returns the provider name, the status code and the upstream message,
+// followed by the message of each failing sub-service.
+func (e *UpstreamError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Service)
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	b.WriteString(" (status ")
+	b.WriteString(strconv.Itoa(e.StatusCode))
+	b.WriteString(")")
+	for _, se := range e.Errors {
+		b.WriteString("; ")
+		b.WriteString(se.Service)
+		b.WriteString(": ")
+		b.WriteString(se.Message)
+	}
+	return b.String()
+}
+
+// Is reports whether the error matches target. A 404 matches ErrNotFound, so the
+// "not found" answers of all the providers can be told apart with errors.Is.
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// FailingServices returns the names of the sub-services that reported an error,
+// in the order the provider listed them.
+func (e *UpstreamError) FailingServices() []string {
+	services := make([]string, 0, len(e.Errors))
+	for _, se := range e.Errors {
+		services = append(services, se.Service)
+	}
+	return services
+}
+
+// LogValue returns a slog.Value representing the UpstreamError,
+// including the failing sub-services for diagnostics.
+func (e *UpstreamError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("service", e.Service),
+		slog.Int("status_code", e.StatusCode),
+		slog.String("message", e.Message),
+		slog.String("type", e.Type),
+		slog.Any("failing_services", e.FailingServices()),
+	)
+}
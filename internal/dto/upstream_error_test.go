@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestUpstreamError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		e    *UpstreamError
+		want string
+	}{
+		{
+			name: "upstream error without sub-services",
+			e:    &UpstreamError{Service: "Viacep", StatusCode: 400, Message: "Verifique a URL: Bad Request"},
+			want: "Viacep: Verifique a URL: Bad Request (status 400)",
+		},
+		{
+			name: "upstream error with sub-services",
+			e: &UpstreamError{
+				Service:    "Brasilapi",
+				StatusCode: 404,
+				Message:    "Todos os serviços de CEP retornaram erro.",
+				Errors: []ServiceError{
+					{Name: "ServiceError", Message: "CEP não encontrado na base dos Correios.", Service: "correios-alt"},
+					{Name: "ServiceError", Message: "Erro ao se conectar com o serviço WideNet.", Service: "widenet"},
+				},
+			},
+			want: "Brasilapi: Todos os serviços de CEP retornaram erro. (status 404); correios-alt: CEP não encontrado na base dos Correios.; widenet: Erro ao se conectar com o serviço WideNet.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Error(); got != tt.want {
+				t.Errorf("UpstreamError.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamError_Is(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "upstream 404 is not found", err: &UpstreamError{Service: "Brasilapi", StatusCode: 404}, want: true},
+		{name: "wrapped upstream 404 is not found", err: fmt.Errorf("query: %w", &UpstreamError{StatusCode: 404}), want: true},
+		{name: "upstream 400 is not not found", err: &UpstreamError{Service: "Viacep", StatusCode: 400}, want: false},
+		{name: "upstream 500 is not not found", err: &UpstreamError{StatusCode: 500}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, ErrNotFound); got != tt.want {
+				t.Errorf("errors.Is(ErrNotFound) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package dto
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
@@ -49,14 +48,29 @@ func NewViacep(cep string, logradouro string, complemento string, unidade string
 	return v, nil
 }
 
+// ViacepEnvelope is the body ViaCEP sends with a 200 status: either an address
+// or {"erro": true} when the cep does not exist.
+type ViacepEnvelope struct {
+	Viacep
+	Erro FlexibleBool `json:"erro"`
+}
+
 // NewViacepFromJson creates a new Viacep instance from a JSON string and validates it.
-// It returns the created Viacep instance or an error if the JSON is invalid or validation fails.
+// The body is decoded with DecodeJson, so a byte order mark, a non UTF-8 charset and
+// unknown fields are tolerated.
+// It returns ErrNotFound if ViaCEP flagged the cep with "erro", either as a boolean
+// or as a string, and an error if the JSON is invalid or validation fails.
+// Otherwise it returns the created Viacep instance.
 func NewViacepFromJson(jsonString string) (*Viacep, error) {
-	var v Viacep
-	err := json.Unmarshal([]byte(jsonString), &v)
+	var e ViacepEnvelope
+	err := DecodeJson([]byte(jsonString), &e)
 	if err != nil {
 		return nil, err
 	}
+	if e.Erro {
+		return nil, ErrNotFound
+	}
+	v := e.Viacep
 	err = v.Validate()
 	if err != nil {
 		return nil, err
@@ -65,24 +79,39 @@ func NewViacepFromJson(jsonString string) (*Viacep, error) {
 }
 
 // Validate validates the Viacep fields and returns an error if any of them are invalid.
-// It checks if the cep is valid, uf is a valid short state name, estado is a valid long state name,
-// regiao is a valid region, localidade, bairro and logradouro are not empty.
+// It checks if the cep is valid and belongs to some state range, uf is a valid short state name, estado and regiao are the
+// state name and region of that uf, localidade is not empty and bairro and logradouro are
+// either both filled or, for a city-level cep, both empty.
 func (v *Viacep) Validate() error {
 	if _, err := shared.ValidateCepWithDash(v.Cep); err != nil {
 		return err
 	}
-	if !shared.ValidateStateShort(v.Uf) {
+	if _, err := shared.ValidateCepRange(v.Cep); err != nil {
+		return err
+	}
+	state, ok := shared.StateByUf(v.Uf)
+	if !ok {
 		return errors.New("uf not found")
 	}
-	if !shared.ValidateStateLong(v.Estado) {
+	estado, ok := shared.StateByName(v.Estado)
+	if !ok {
 		return errors.New("estado not found")
 	}
-	if !shared.ValidateRegiao(v.Regiao) {
+	if estado.Uf != state.Uf {
+		return errors.New("estado does not match uf")
+	}
+	regiao, ok := shared.RegionByName(v.Regiao)
+	if !ok {
 		return errors.New("regiao not found")
 	}
-	if v.Localidade == "" || v.Bairro == "" || v.Logradouro == "" {
-		return errors.New("localidade, bairro and logradouro must not be empty")
+	if regiao != state.Region {
+		return errors.New("regiao does not match uf")
 	}
+	return validateLocality(v.Localidade, v.Bairro, v.Logradouro)
+}
 
-	return nil
+// IsCityLevel reports whether the Viacep record covers a whole city,
+// that is, it has no bairro and no logradouro.
+func (v *Viacep) IsCityLevel() bool {
+	return isCityLevel(v.Bairro, v.Logradouro)
 }
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	viacepTitleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	viacepH1Regex    = regexp.MustCompile(`(?is)<h1[^>]*>(.*?)</h1>`)
+	viacepH3Regex    = regexp.MustCompile(`(?is)<h3[^>]*>(.*?)</h3>`)
+	tagRegex         = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// ViacepError is the HTML page ViaCEP sends with a 400 status, such as
+// "ViaCEP 400" / "Http 400" / "Verifique a URL" / "{Bad Request}".
+type ViacepError struct {
+	Title    string
+	Status   string
+	Messages []string
+}
+
+// NewViacepErrorFromHtml creates a new ViacepError instance from the HTML error page
+// sent by ViaCEP, and validates it.
+// It returns the created ViacepError instance or an error if the page has neither a
+// title nor a heading.
+func NewViacepErrorFromHtml(htmlString string) (*ViacepError, error) {
+	v := ViacepError{
+		Title:  firstText(viacepTitleRegex, htmlString),
+		Status: firstText(viacepH1Regex, htmlString),
+	}
+	for _, m := range viacepH3Regex.FindAllStringSubmatch(htmlString, -1) {
+		if text := strings.Trim(cleanText(m[1]), "{}"); text != "" {
+			v.Messages = append(v.Messages, text)
+		}
+	}
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Validate checks that the page had a title or a heading, which tells an
+// actual ViaCEP error page apart from an arbitrary body.
+func (v *ViacepError) Validate() error {
+	if v.Title == "" && v.Status == "" {
+		return errors.New("html error page has no title and no heading")
+	}
+	return nil
+}
+
+// Message returns the messages of the page joined by ": ",
+// such as "Verifique a URL: Bad Request", or the status heading if there are none.
+func (v *ViacepError) Message() string {
+	if len(v.Messages) == 0 {
+		if v.Status != "" {
+			return v.Status
+		}
+		return v.Title
+	}
+	return strings.Join(v.Messages, ": ")
+}
+
+// ToUpstreamError converts the ViacepError into an UpstreamError for the given
+// service name and HTTP status code.
+func (v *ViacepError) ToUpstreamError(service string, statusCode int) *UpstreamError {
+	return &UpstreamError{
+		Service:    service,
+		StatusCode: statusCode,
+		Message:    v.Message(),
+		Name:       v.Title,
+	}
+}
+
+// firstText returns the text inside the first match of re in s, or "" if there is none.
+func firstText(re *regexp.Regexp, s string) string {
+	m := re.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return cleanText(m[1])
+}
+
+// cleanText removes inner tags, unescapes entities and collapses whitespace.
+func cleanText(s string) string {
+	s = html.UnescapeString(tagRegex.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewViacepErrorFromHtml(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		htmlString  string
+		want        *ViacepError
+		wantMessage string
+		wantErr     bool
+	}{
+		{
+			name:    "viacep 400 fixture",
+			fixture: "../../responses/viacep.400.html",
+			want: &ViacepError{
+				Title:    "ViaCEP 400",
+				Status:   "Http 400",
+				Messages: []string{"Verifique a URL", "Bad Request"},
+			},
+			wantMessage: "Verifique a URL: Bad Request",
+			wantErr:     false,
+		},
+		{
+			name:       "viacep error page without messages",
+			htmlString: `<html><head><title>ViaCEP 500</title></head><body><h1>Http 500</h1></body></html>`,
+			want: &ViacepError{
+				Title:  "ViaCEP 500",
+				Status: "Http 500",
+			},
+			wantMessage: "Http 500",
+			wantErr:     false,
+		},
+		{
+			name:       "viacep error page not html",
+			htmlString: `{"erro": "true"}`,
+			want:       nil,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			htmlString := tt.htmlString
+			if tt.fixture != "" {
+				b, err := os.ReadFile(tt.fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				htmlString = string(b)
+			}
+			got, err := NewViacepErrorFromHtml(htmlString)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewViacepErrorFromHtml() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewViacepErrorFromHtml() = %v, want %v", got, tt.want)
+			}
+			if got != nil && got.Message() != tt.wantMessage {
+				t.Errorf("ViacepError.Message() = %v, want %v", got.Message(), tt.wantMessage)
+			}
+		})
+	}
+}
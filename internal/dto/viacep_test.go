@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"errors"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -345,6 +347,74 @@ func TestViacep_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "validate viacep estado does not match uf",
+			v: &Viacep{
+				Cep:         "39408-078",
+				Logradouro:  "Avenida Herlindo Silveira",
+				Complemento: "Apto 101",
+				Unidade:     "Sala 101",
+				Bairro:      "Centro",
+				Localidade:  "Montes Claros",
+				Uf:          "MG",
+				Estado:      "Espírito Santo",
+				Regiao:      "Sudeste",
+				Ibge:        "3143302",
+				Gia:         "",
+				Ddd:         "38",
+				Siafi:       "4865",
+			},
+			wantErr: true,
+		},
+		{
+			name: "validate viacep regiao does not match uf",
+			v: &Viacep{
+				Cep:         "39408-078",
+				Logradouro:  "Avenida Herlindo Silveira",
+				Complemento: "Apto 101",
+				Unidade:     "Sala 101",
+				Bairro:      "Centro",
+				Localidade:  "Montes Claros",
+				Uf:          "MG",
+				Estado:      "Minas Gerais",
+				Regiao:      "Nordeste",
+				Ibge:        "3143302",
+				Gia:         "",
+				Ddd:         "38",
+				Siafi:       "4865",
+			},
+			wantErr: true,
+		},
+		{
+			name: "validate viacep paraiba",
+			v: &Viacep{
+				Cep:        "58010-000",
+				Logradouro: "Rua Duque de Caxias",
+				Bairro:     "Centro",
+				Localidade: "João Pessoa",
+				Uf:         "PB",
+				Estado:     "Paraíba",
+				Regiao:     "Nordeste",
+				Ibge:       "2507507",
+				Ddd:        "83",
+				Siafi:      "2051",
+			},
+			wantErr: false,
+		},
+		{
+			name: "validate viacep city level",
+			v: &Viacep{
+				Cep:        "39270-000",
+				Localidade: "Lassance",
+				Uf:         "MG",
+				Estado:     "Minas Gerais",
+				Regiao:     "Sudeste",
+				Ibge:       "3137106",
+				Ddd:        "38",
+				Siafi:      "4755",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -354,3 +424,99 @@ func TestViacep_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestViacep_IsCityLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		v    *Viacep
+		want bool
+	}{
+		{
+			name: "street level viacep",
+			v: &Viacep{
+				Cep:        "39408-078",
+				Logradouro: "Avenida Herlindo Silveira",
+				Bairro:     "Ibituruna",
+				Localidade: "Montes Claros",
+			},
+			want: false,
+		},
+		{
+			name: "city level viacep",
+			v: &Viacep{
+				Cep:        "39270-000",
+				Localidade: "Lassance",
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.IsCityLevel(); got != tt.want {
+				t.Errorf("Viacep.IsCityLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewViacepFromJsonFixtures(t *testing.T) {
+	want := &Viacep{
+		Cep:         "39408-078",
+		Logradouro:  "Avenida Herlindo Silveira",
+		Complemento: "até 499/500",
+		Unidade:     "",
+		Bairro:      "Ibituruna",
+		Localidade:  "Montes Claros",
+		Uf:          "MG",
+		Estado:      "Minas Gerais",
+		Regiao:      "Sudeste",
+		Ibge:        "3143302",
+		Gia:         "",
+		Ddd:         "38",
+		Siafi:       "4865",
+	}
+	tests := []struct {
+		name        string
+		fixture     string
+		want        *Viacep
+		wantErr     bool
+		wantErrType error
+	}{
+		{name: "viacep 200", fixture: "viacep.200.json", want: want},
+		{name: "viacep 200 with byte order mark", fixture: "viacep.200.bom.json", want: want},
+		{name: "viacep 200 in latin1", fixture: "viacep.200.latin1.json", want: want},
+		{name: "viacep 200 with unknown fields", fixture: "viacep.200.extra.json", want: want},
+		{name: "viacep erro as string", fixture: "viacep.200.erro.json", wantErr: true, wantErrType: ErrNotFound},
+		{name: "viacep erro as boolean", fixture: "viacep.200.erro.bool.json", wantErr: true, wantErrType: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := os.ReadFile("../../responses/" + tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := NewViacepFromJson(string(b))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewViacepFromJson() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErrType != nil && !errors.Is(err, tt.wantErrType) {
+				t.Errorf("NewViacepFromJson() error = %v, want %v", err, tt.wantErrType)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewViacepFromJson() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewViacepFromJsonErroInStreetName(t *testing.T) {
+	jsonString := `{"cep":"39408-078","logradouro":"Rua \"erro\": \"true\"","bairro":"Ibituruna","localidade":"Montes Claros","uf":"MG","estado":"Minas Gerais","regiao":"Sudeste"}`
+	got, err := NewViacepFromJson(jsonString)
+	if err != nil {
+		t.Fatalf("NewViacepFromJson() error = %v, want nil", err)
+	}
+	if got.Logradouro != `Rua "erro": "true"` {
+		t.Errorf("NewViacepFromJson() logradouro = %v", got.Logradouro)
+	}
+}
@@ -0,0 +1,257 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+// Target makes one lookup of cep and returns the provider that won it.
+type Target func(ctx context.Context, cep string) (provider string, err error)
+
+// Options tell how hard and how long the load test drives its target.
+type Options struct {
+	// Rate is the number of lookups started per second. A rate above one per
+	// nanosecond, or not positive, starts them one per nanosecond at most.
+	Rate float64
+	// Duration is how long lookups are started for; the ones in flight are then awaited.
+	Duration time.Duration
+	// MaxInFlight bounds the lookups in flight; a lookup due while the bound is
+	// reached is dropped and counted, so a slow target does not pile up goroutines.
+	MaxInFlight int
+	// Ceps are looked up in turn.
+	Ceps []string
+}
+
+// Report is the outcome of a load test.
+type Report struct {
+	Requests  int `json:"requests"`
+	Succeeded int `json:"succeeded"`
+	Dropped   int `json:"dropped"`
+	// Elapsed is the time from the first lookup to the end of the last one.
+	Elapsed time.Duration `json:"elapsed"`
+	// Rate is the number of lookups finished per second.
+	Rate float64       `json:"rate"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+	// Winners counts the lookups won by each provider.
+	Winners map[string]int `json:"winners"`
+	// Errors counts the failed lookups by kind, as returned by ErrorKind.
+	Errors map[string]int `json:"errors"`
+	// AllocsPerLookup and BytesPerLookup are the heap allocations of the whole
+	// process, the mock providers included, divided by the lookups.
+	AllocsPerLookup float64 `json:"allocs_per_lookup"`
+	BytesPerLookup  float64 `json:"bytes_per_lookup"`
+	// MaxGoroutines is the most goroutines seen while lookups were started, and
+	// GoroutinesAfter the goroutines left once all of them ended.
+	MaxGoroutines   int `json:"max_goroutines"`
+	GoroutinesAfter int `json:"goroutines_after"`
+}
+
+// Run starts lookups of opts.Ceps on target at opts.Rate for opts.Duration, or
+// until ctx is done, waits for the ones in flight and reports on them.
+func Run(ctx context.Context, opts Options, target Target) Report {
+	report := Report{Winners: map[string]int{}, Errors: map[string]int{}}
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	sem := make(chan struct{}, max(opts.MaxInFlight, 1))
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	// time.NewTicker panics on an interval that is not positive.
+	interval := time.Duration(1)
+	if opts.Rate > 0 {
+		interval = max(time.Duration(float64(time.Second)/opts.Rate), interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.After(opts.Duration)
+	for i := 0; ; i++ {
+		report.MaxGoroutines = max(report.MaxGoroutines, runtime.NumGoroutine())
+		select {
+		case sem <- struct{}{}:
+			cep := opts.Ceps[i%len(opts.Ceps)]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				begin := time.Now()
+				provider, err := target(ctx, cep)
+				latency := time.Since(begin)
+				mu.Lock()
+				defer mu.Unlock()
+				latencies = append(latencies, latency)
+				if err != nil {
+					report.Errors[ErrorKind(err)]++
+					return
+				}
+				report.Succeeded++
+				report.Winners[provider]++
+			}()
+		default:
+			report.Dropped++
+		}
+		select {
+		case <-ticker.C:
+			continue
+		case <-deadline:
+		case <-ctx.Done():
+		}
+		break
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	report.Requests = len(latencies)
+	if report.Requests > 0 {
+		report.AllocsPerLookup = float64(after.Mallocs-before.Mallocs) / float64(report.Requests)
+		report.BytesPerLookup = float64(after.TotalAlloc-before.TotalAlloc) / float64(report.Requests)
+	}
+	report.Rate = float64(report.Requests) / report.Elapsed.Seconds()
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 50)
+	report.P95 = percentile(latencies, 95)
+	report.P99 = percentile(latencies, 99)
+	report.Max = percentile(latencies, 100)
+	report.GoroutinesAfter = runtime.NumGoroutine()
+	return report
+}
+
+// percentile returns the p-th percentile of the sorted latencies, by the nearest
+// rank, or 0 if there are none.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// ErrorKind returns the kind of a lookup error: "invalid_cep", "not_found",
+// "all_failed", "timeout", "canceled" or "other".
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCep):
+		return "invalid_cep"
+	case errors.Is(err, dto.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, usecase.ErrAllProvidersFailed):
+		return "all_failed"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "other"
+	}
+}
+
+// LookupTarget returns a target that races the providers in-process with
+// usecase.Lookup, each lookup bounded by timeout.
+func LookupTarget(opts usecase.RaceOptions, timeout time.Duration) Target {
+	return func(ctx context.Context, cep string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		result, err := usecase.Lookup(ctx, cep, opts)
+		return result.Provider, err
+	}
+}
+
+// ServerTarget returns a target that looks the ceps up on the "serve" command
+// listening at baseUrl, such as "http://localhost:8080". Its statuses are turned
+// back into the errors of usecase.Lookup: 400 into ErrInvalidCep, 404 into
+// ErrCepNotFound, 504 into a timeout and 502 into ErrAllProvidersFailed.
+func ServerTarget(client *http.Client, baseUrl string) Target {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+	return func(ctx context.Context, cep string) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", baseUrl+"/cep/"+url.PathEscape(cep), nil)
+		if err != nil {
+			return "", err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+		if err != nil {
+			return "", err
+		}
+		switch res.StatusCode {
+		case http.StatusOK:
+		case http.StatusBadRequest:
+			return "", usecase.ErrInvalidCep
+		case http.StatusNotFound:
+			return "", usecase.ErrCepNotFound
+		case http.StatusGatewayTimeout:
+			return "", context.DeadlineExceeded
+		case http.StatusBadGateway:
+			return "", usecase.ErrAllProvidersFailed
+		default:
+			return "", fmt.Errorf("server answered %s", res.Status)
+		}
+		var result usecase.Result
+		if err := json.Unmarshal(body, &result); err != nil {
+			return "", err
+		}
+		return result.Provider, nil
+	}
+}
+
+// Write writes the report as text: the lookups, the latency percentiles, the
+// winners and errors, sorted by count, and the resources used.
+func (r Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "lookups:    %d in %s (%.1f/s), %d succeeded, %d dropped\n",
+		r.Requests, r.Elapsed.Round(time.Millisecond), r.Rate, r.Succeeded, r.Dropped)
+	fmt.Fprintf(w, "latency:    p50 %s  p95 %s  p99 %s  max %s\n",
+		r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+	writeCounts(w, "winners:", r.Winners, r.Requests)
+	writeCounts(w, "errors:", r.Errors, r.Requests)
+	fmt.Fprintf(w, "allocs:     %.0f allocs, %.0f bytes per lookup\n", r.AllocsPerLookup, r.BytesPerLookup)
+	fmt.Fprintf(w, "goroutines: %d at most, %d after\n", r.MaxGoroutines, r.GoroutinesAfter)
+}
+
+// writeCounts writes one line per key of counts, the largest first, with its
+// share of total.
+func writeCounts(w io.Writer, title string, counts map[string]int, total int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) == 0 {
+		fmt.Fprintf(w, "%-11s none\n", title)
+		return
+	}
+	for i, k := range keys {
+		if i > 0 {
+			title = ""
+		}
+		fmt.Fprintf(w, "%-11s %-14s %6d  %5.1f%%\n", title, k, counts[k], 100*float64(counts[k])/float64(total))
+	}
+}
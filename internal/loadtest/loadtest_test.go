@@ -0,0 +1,136 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/usecase"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{p: 50, want: 50 * time.Millisecond},
+		{p: 95, want: 95 * time.Millisecond},
+		{p: 99, want: 99 * time.Millisecond},
+		{p: 100, want: 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(latencies, tt.p); got != tt.want {
+			t.Errorf("percentile(%d) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	target := func(ctx context.Context, cep string) (string, error) {
+		switch cep {
+		case "39408078":
+			return "viacep", nil
+		case "39408079":
+			return "", usecase.ErrCepNotFound
+		default:
+			return "", context.DeadlineExceeded
+		}
+	}
+	report := Run(context.Background(), Options{
+		Rate:        1000,
+		Duration:    60 * time.Millisecond,
+		MaxInFlight: 10,
+		Ceps:        []string{"39408078", "39408078", "39408079", "01001000"},
+	}, target)
+	if report.Requests < 8 || report.Dropped != 0 {
+		t.Fatalf("Run() = %+v, want at least 8 lookups and none dropped", report)
+	}
+	if report.Succeeded != report.Winners["viacep"] || report.Errors["not_found"] == 0 || report.Errors["timeout"] == 0 {
+		t.Errorf("Run() winners = %v, errors = %v", report.Winners, report.Errors)
+	}
+	var out strings.Builder
+	report.Write(&out)
+	for _, want := range []string{"lookups:", "p50", "viacep", "not_found", "goroutines:"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Write() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestRunDrops(t *testing.T) {
+	release := make(chan struct{})
+	target := func(ctx context.Context, cep string) (string, error) {
+		<-release
+		return "viacep", nil
+	}
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+	report := Run(context.Background(), Options{Rate: 1000, Duration: 30 * time.Millisecond, MaxInFlight: 2, Ceps: []string{"39408078"}}, target)
+	if report.Requests != 2 || report.Dropped == 0 {
+		t.Errorf("Run() = %d lookups, %d dropped, want 2 and some dropped", report.Requests, report.Dropped)
+	}
+}
+
+func TestRunRate(t *testing.T) {
+	target := func(ctx context.Context, cep string) (string, error) {
+		return "viacep", nil
+	}
+	for _, rate := range []float64{0, -1, 2e9} {
+		t.Run(fmt.Sprint(rate), func(t *testing.T) {
+			report := Run(context.Background(), Options{Rate: rate, Duration: 5 * time.Millisecond, MaxInFlight: 1, Ceps: []string{"39408078"}}, target)
+			if report.Requests == 0 {
+				t.Errorf("Run() = %+v, want some lookups", report)
+			}
+		})
+	}
+}
+
+func TestServerTarget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cep/39408078":
+			w.Write([]byte(`{"cep": {"cep": "39408078"}, "provider": "brasilapi-v2", "service": "BrasilapiV2"}`))
+		case "/cep/123":
+			w.WriteHeader(http.StatusBadRequest)
+		case "/cep/39408079":
+			w.WriteHeader(http.StatusNotFound)
+		case "/cep/01001000":
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	target := ServerTarget(server.Client(), server.URL+"/")
+	tests := []struct {
+		cep          string
+		wantProvider string
+		wantKind     string
+	}{
+		{cep: "39408078", wantProvider: "brasilapi-v2"},
+		{cep: "123", wantKind: "invalid_cep"},
+		{cep: "39408079", wantKind: "not_found"},
+		{cep: "01001000", wantKind: "timeout"},
+		{cep: "20000000", wantKind: "all_failed"},
+	}
+	for _, tt := range tests {
+		provider, err := target(context.Background(), tt.cep)
+		kind := ""
+		if err != nil {
+			kind = ErrorKind(err)
+		}
+		if provider != tt.wantProvider || kind != tt.wantKind {
+			t.Errorf("target(%s) = %q, %q, want %q, %q", tt.cep, provider, kind, tt.wantProvider, tt.wantKind)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the providers emulated by the server.
+const (
+	Brasilapi = "brasilapi"
+	Viacep    = "viacep"
+)
+
+// Latency distributions.
+const (
+	Fixed   = "fixed"
+	Uniform = "uniform"
+	Normal  = "normal"
+)
+
+// Scenario tells how each emulated provider behaves.
+type Scenario struct {
+	// Seed seeds the random latencies and errors, so a run can be repeated.
+	Seed int64 `json:"seed"`
+	// Providers maps Brasilapi and Viacep to their behavior; a provider left out
+	// answers at once from the fixtures.
+	Providers map[string]Behavior `json:"providers"`
+}
+
+// Behavior is how a provider answers.
+type Behavior struct {
+	Latency Latency `json:"latency"`
+	// ErrorRate is the probability, from 0 to 1, of answering ErrorStatus instead.
+	ErrorRate float64 `json:"error_rate"`
+	// ErrorStatus is the status of the injected errors; 0 means 500.
+	ErrorStatus int `json:"error_status"`
+	// Ceps maps a cep to the status it always gets, such as 404 or 503. A 200
+	// answers the 200 fixture, which holds the address of its own cep, so it is
+	// only meant for that cep.
+	Ceps map[string]int `json:"ceps"`
+}
+
+// Latency is how long a provider takes to answer: Min for a Fixed distribution,
+// between Min and Max for Uniform, and around Mean, with Stddev, for Normal, never
+// below Min.
+type Latency struct {
+	// Distribution is Fixed, the default, Uniform or Normal.
+	Distribution string   `json:"distribution"`
+	Min          Duration `json:"min"`
+	Max          Duration `json:"max"`
+	Mean         Duration `json:"mean"`
+	Stddev       Duration `json:"stddev"`
+}
+
+// sample draws a latency from the distribution.
+func (l Latency) sample(r *rand.Rand) time.Duration {
+	switch l.Distribution {
+	case Uniform:
+		if l.Max.Duration <= l.Min.Duration {
+			return l.Min.Duration
+		}
+		return l.Min.Duration + time.Duration(r.Int63n(int64(l.Max.Duration-l.Min.Duration)))
+	case Normal:
+		d := time.Duration(r.NormFloat64()*float64(l.Stddev.Duration)) + l.Mean.Duration
+		return max(d, l.Min.Duration)
+	default:
+		return l.Min.Duration
+	}
+}
+
+// Duration is a time.Duration written in JSON as a string, such as "150ms".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("duration must be a string, such as \"150ms\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.New("invalid duration " + strconv.Quote(s))
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// fixed returns a behavior that answers after d.
+func fixed(d time.Duration) Behavior {
+	return Behavior{Latency: Latency{Min: Duration{d}}}
+}
+
+// Scenarios are the built-in scenarios, by name. The latencies of the loser are
+// far above the usual timeouts, so the winner does not depend on the random delay
+// the CLI waits before each query.
+var Scenarios = map[string]Scenario{
+	"brasilapi-wins": {Providers: map[string]Behavior{Brasilapi: fixed(50 * time.Millisecond), Viacep: fixed(time.Minute)}},
+	"viacep-wins":    {Providers: map[string]Behavior{Brasilapi: fixed(time.Minute), Viacep: fixed(50 * time.Millisecond)}},
+	"both-timeout":   {Providers: map[string]Behavior{Brasilapi: fixed(time.Minute), Viacep: fixed(time.Minute)}},
+	"flaky": {Seed: 1, Providers: map[string]Behavior{
+		Brasilapi: {Latency: Latency{Distribution: Normal, Mean: Duration{200 * time.Millisecond}, Stddev: Duration{100 * time.Millisecond}}, ErrorRate: 0.3, ErrorStatus: 503},
+		Viacep:    {Latency: Latency{Distribution: Uniform, Min: Duration{100 * time.Millisecond}, Max: Duration{800 * time.Millisecond}}, ErrorRate: 0.2},
+	}},
+}
+
+// ScenarioNames returns the names of the built-in scenarios, sorted.
+func ScenarioNames() []string {
+	names := make([]string, 0, len(Scenarios))
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadScenario returns the built-in scenario with the given name or, if there is
+// none, the scenario in the JSON file at nameOrPath. Unknown fields are rejected.
+func LoadScenario(nameOrPath string) (Scenario, error) {
+	if s, ok := Scenarios[nameOrPath]; ok {
+		return s, nil
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return Scenario{}, errors.New("no scenario named " + nameOrPath + " (built-in: " + strings.Join(ScenarioNames(), ", ") + ") and " + err.Error())
+	}
+	var s Scenario
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return Scenario{}, errors.New(nameOrPath + ": " + err.Error())
+	}
+	return s, s.Validate()
+}
+
+// Validate returns an error for an unknown provider or distribution, an error rate
+// outside 0 to 1, or a status that is not a valid HTTP status.
+func (s Scenario) Validate() error {
+	names := make([]string, 0, len(s.Providers))
+	for name := range s.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		b := s.Providers[name]
+		if name != Brasilapi && name != Viacep {
+			errs = append(errs, errors.New("unknown provider "+name+", want "+Brasilapi+" or "+Viacep))
+			continue
+		}
+		switch b.Latency.Distribution {
+		case "", Fixed, Uniform, Normal:
+		default:
+			errs = append(errs, errors.New(name+": unknown distribution "+b.Latency.Distribution))
+		}
+		if b.ErrorRate < 0 || b.ErrorRate > 1 {
+			errs = append(errs, errors.New(name+": error_rate must be between 0 and 1"))
+		}
+		if b.ErrorStatus != 0 && (b.ErrorStatus < 100 || b.ErrorStatus > 599) {
+			errs = append(errs, errors.New(name+": invalid error_status "+strconv.Itoa(b.ErrorStatus)))
+		}
+		for cep, status := range b.Ceps {
+			if status < 100 || status > 599 {
+				errs = append(errs, errors.New(name+": invalid status "+strconv.Itoa(status)+" for cep "+cep))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package mockserver
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "built-in", path: "viacep-wins"},
+		{name: "example file", path: "../../scenario.example.json"},
+		{name: "missing", path: "nowhere", wantErr: "no scenario named nowhere (built-in: both-timeout, brasilapi-wins, flaky, viacep-wins)"},
+		{name: "unknown field", path: write("field.json", `{"providers": {"viacep": {"delay": "1s"}}}`), wantErr: `unknown field "delay"`},
+		{name: "invalid duration", path: write("duration.json", `{"providers": {"viacep": {"latency": {"min": "soon"}}}}`), wantErr: `invalid duration "soon"`},
+		{
+			name:    "invalid values",
+			path:    write("values.json", `{"providers": {"postmon": {}, "viacep": {"latency": {"distribution": "pareto"}, "error_rate": 2, "ceps": {"01001000": 1000}}}}`),
+			wantErr: "unknown provider postmon, want brasilapi or viacep\nviacep: unknown distribution pareto\nviacep: error_rate must be between 0 and 1\nviacep: invalid status 1000 for cep 01001000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadScenario(tt.path)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("LoadScenario() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLatency_sample(t *testing.T) {
+	ms := func(n int) Duration { return Duration{time.Duration(n) * time.Millisecond} }
+	tests := []struct {
+		name     string
+		latency  Latency
+		min, max time.Duration
+	}{
+		{name: "fixed", latency: Latency{Min: ms(50)}, min: 50 * time.Millisecond, max: 50 * time.Millisecond},
+		{name: "uniform", latency: Latency{Distribution: Uniform, Min: ms(10), Max: ms(20)}, min: 10 * time.Millisecond, max: 20 * time.Millisecond},
+		{name: "normal above min", latency: Latency{Distribution: Normal, Mean: ms(10), Stddev: ms(50), Min: ms(5)}, min: 5 * time.Millisecond, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for range 100 {
+				if got := tt.latency.sample(r); got < tt.min || got > tt.max {
+					t.Fatalf("sample() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
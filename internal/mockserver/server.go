@@ -0,0 +1,179 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+// route is an endpoint emulated by the server: the provider whose Behavior it
+// follows and the fixtures it answers with, by status.
+type route struct {
+	pattern  string
+	provider string
+	fixtures map[int]string
+	// notFound is the status of a cep missing from the fixtures, answered with
+	// notFoundFixture, if set, or the fixture of the status; ViaCEP answers 200
+	// with {"erro": "true"}.
+	notFound        int
+	notFoundFixture string
+}
+
+// routes are the endpoints of Brasilapi, v1 and v2, and ViaCEP, with the fixtures of
+// the responses folder.
+var routes = []route{
+	{
+		pattern:  "GET /api/cep/v1/{cep}",
+		provider: Brasilapi,
+		fixtures: map[int]string{200: "brasilapi.200.json", 400: "brasilapi.400.json", 404: "brasilapi.404.json"},
+		notFound: 404,
+	},
+	{
+		pattern:  "GET /api/cep/v2/{cep}",
+		provider: Brasilapi,
+		fixtures: map[int]string{200: "brasilapi.v2.200.json", 400: "brasilapi.400.json", 404: "brasilapi.404.json"},
+		notFound: 404,
+	},
+	{
+		pattern:         "GET /ws/{cep}/json/",
+		provider:        Viacep,
+		fixtures:        map[int]string{200: "viacep.200.json", 400: "viacep.400.html"},
+		notFound:        200,
+		notFoundFixture: "viacep.200.erro.json",
+	},
+}
+
+// Server emulates the providers from the captured responses, following a Scenario.
+type Server struct {
+	scenario Scenario
+	// files are the fixtures read, by name, and ceps the cep of each 200 fixture.
+	files map[string][]byte
+	ceps  map[string]string
+	mux   *http.ServeMux
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New returns a server answering with the fixtures of the responses folder at dir,
+// as the given scenario tells.
+// It returns an error if a fixture cannot be read or the scenario is invalid.
+func New(dir string, scenario Scenario) (*Server, error) {
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Server{
+		scenario: scenario,
+		files:    map[string][]byte{},
+		ceps:     map[string]string{},
+		mux:      http.NewServeMux(),
+		rand:     rand.New(rand.NewSource(scenario.Seed)),
+	}
+	for _, rt := range routes {
+		names := []string{rt.notFoundFixture}
+		for _, name := range rt.fixtures {
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if name == "" || s.files[name] != nil {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			s.files[name] = data
+		}
+		var found struct {
+			Cep string `json:"cep"`
+		}
+		if err := json.Unmarshal(s.files[rt.fixtures[200]], &found); err != nil {
+			return nil, err
+		}
+		s.ceps[rt.fixtures[200]] = strings.ReplaceAll(found.Cep, "-", "")
+		s.mux.HandleFunc(rt.pattern, s.handler(rt))
+	}
+	return s, nil
+}
+
+// ServeHTTP answers a request to one of the emulated endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handler returns the handler of the route: it waits the latency of the provider,
+// unless the client gives up first, and answers with the status the scenario gives
+// the cep, an injected error, or the fixture of the cep.
+func (s *Server) handler(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cep := r.PathValue("cep")
+		behavior := s.scenario.Providers[rt.provider]
+		latency, status := s.draw(behavior)
+		if forced, ok := behavior.Ceps[cep]; ok {
+			status = forced
+		}
+		select {
+		case <-r.Context().Done():
+			slog.Info("mockserver: client gone", "provider", rt.provider, "cep", cep, "latency", latency.String())
+			return
+		case <-time.After(latency):
+		}
+
+		name := ""
+		switch {
+		case status != 0:
+			name = rt.fixtures[status]
+		case !validCep(cep):
+			status, name = http.StatusBadRequest, rt.fixtures[http.StatusBadRequest]
+		case cep == s.ceps[rt.fixtures[200]]:
+			status, name = http.StatusOK, rt.fixtures[http.StatusOK]
+		default:
+			status, name = rt.notFound, rt.fixtures[rt.notFound]
+			if rt.notFoundFixture != "" {
+				name = rt.notFoundFixture
+			}
+		}
+		slog.Info("mockserver: answered", "provider", rt.provider, "cep", cep, "status", status, "latency", latency.String())
+		if name == "" {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		contentType := "application/json; charset=utf-8"
+		if strings.HasSuffix(name, ".html") {
+			contentType = "text/html; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write(s.files[name])
+	}
+}
+
+// validCep reports whether cep has 8 digits, as the providers require.
+func validCep(cep string) bool {
+	ok, _ := shared.ValidateCepWithoutDash(cep)
+	return ok
+}
+
+// draw draws the latency of a request and whether it fails, returning the error
+// status, or 0 if it does not fail. The draws share one source, seeded by the
+// scenario, so the same sequence of requests gets the same answers.
+func (s *Server) draw(b Behavior) (time.Duration, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latency := b.Latency.sample(s.rand)
+	if b.ErrorRate == 0 || s.rand.Float64() >= b.ErrorRate {
+		return latency, 0
+	}
+	if b.ErrorStatus == 0 {
+		return latency, http.StatusInternalServerError
+	}
+	return latency, b.ErrorStatus
+}
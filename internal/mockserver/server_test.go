@@ -0,0 +1,131 @@
+package mockserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, scenario Scenario) *httptest.Server {
+	t.Helper()
+	s, err := New("../../responses", scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServer(t *testing.T) {
+	scenario := Scenario{Providers: map[string]Behavior{
+		Brasilapi: {Ceps: map[string]int{"01001000": 503}},
+		Viacep:    {ErrorRate: 1, ErrorStatus: 502},
+	}}
+	server := newTestServer(t, scenario)
+	tests := []struct {
+		name            string
+		path            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "brasilapi v1 found", path: "/api/cep/v1/39408078", wantStatus: 200, wantContentType: "application/json", wantBody: `"city": "Montes Claros"`},
+		{name: "brasilapi v2 found", path: "/api/cep/v2/39408078", wantStatus: 200, wantContentType: "application/json", wantBody: `"location"`},
+		{name: "brasilapi not found", path: "/api/cep/v2/39408079", wantStatus: 404, wantContentType: "application/json", wantBody: "CepPromiseError"},
+		{name: "brasilapi invalid", path: "/api/cep/v1/3940807", wantStatus: 400, wantContentType: "application/json", wantBody: "8 caracteres"},
+		{name: "brasilapi status by cep", path: "/api/cep/v1/01001000", wantStatus: 503, wantBody: "Service Unavailable"},
+		{name: "viacep injected error", path: "/ws/39408078/json/", wantStatus: 502, wantBody: "Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("GET %s = %d %q, want %d with %q", tt.path, res.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, tt.wantContentType) {
+				t.Errorf("GET %s Content-Type = %q, want %q", tt.path, contentType, tt.wantContentType)
+			}
+		})
+	}
+}
+
+func TestServerViacepFixtures(t *testing.T) {
+	server := newTestServer(t, Scenario{})
+	tests := []struct {
+		path            string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{path: "/ws/39408078/json/", wantStatus: 200, wantContentType: "application/json", wantBody: `"localidade": "Montes Claros"`},
+		{path: "/ws/39408079/json/", wantStatus: 200, wantContentType: "application/json", wantBody: `"erro": "true"`},
+		{path: "/ws/3940807/json/", wantStatus: 400, wantContentType: "text/html", wantBody: "<!DOCTYPE HTML>"},
+	}
+	for _, tt := range tests {
+		res, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != tt.wantStatus || !strings.Contains(string(body), tt.wantBody) ||
+			!strings.HasPrefix(res.Header.Get("Content-Type"), tt.wantContentType) {
+			t.Errorf("GET %s = %d %s %q, want %d %s with %q", tt.path, res.StatusCode, res.Header.Get("Content-Type"), body, tt.wantStatus, tt.wantContentType, tt.wantBody)
+		}
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	server := newTestServer(t, Scenarios["brasilapi-wins"])
+
+	start := time.Now()
+	res, err := http.Get(server.URL + "/api/cep/v2/39408078")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("brasilapi answered in %v, want at least 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/ws/39408078/json/", nil)
+	if _, err := http.DefaultClient.Do(req); err == nil {
+		t.Error("viacep answered, want it to outlast the client timeout")
+	}
+}
+
+func TestServerSeed(t *testing.T) {
+	scenario := Scenario{Seed: 7, Providers: map[string]Behavior{Viacep: {ErrorRate: 0.5}}}
+	statuses := func() string {
+		server := newTestServer(t, scenario)
+		var got []string
+		for range 20 {
+			res, err := http.Get(server.URL + "/ws/39408078/json/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			got = append(got, res.Status[:3])
+		}
+		return strings.Join(got, ",")
+	}
+	first, second := statuses(), statuses()
+	if first != second {
+		t.Errorf("statuses differ with the same seed: %s and %s", first, second)
+	}
+	if !strings.Contains(first, "200") || !strings.Contains(first, "500") {
+		t.Errorf("statuses = %s, want both 200 and 500 with an error rate of 0.5", first)
+	}
+}
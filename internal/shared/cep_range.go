@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CepRange is an inclusive range of ceps assigned by Correios to a UF.
+// Start and End are the 8 digits of the cep as integers, so 01000-000 is 1000000.
+type CepRange struct {
+	Uf    string
+	Start int
+	End   int
+}
+
+var cepRanges = []CepRange{
+	{Uf: "SP", Start: 1000000, End: 19999999},
+	{Uf: "RJ", Start: 20000000, End: 28999999},
+	{Uf: "ES", Start: 29000000, End: 29999999},
+	{Uf: "MG", Start: 30000000, End: 39999999},
+	{Uf: "BA", Start: 40000000, End: 48999999},
+	{Uf: "SE", Start: 49000000, End: 49999999},
+	{Uf: "PE", Start: 50000000, End: 56999999},
+	{Uf: "AL", Start: 57000000, End: 57999999},
+	{Uf: "PB", Start: 58000000, End: 58999999},
+	{Uf: "RN", Start: 59000000, End: 59999999},
+	{Uf: "CE", Start: 60000000, End: 63999999},
+	{Uf: "PI", Start: 64000000, End: 64999999},
+	{Uf: "MA", Start: 65000000, End: 65999999},
+	{Uf: "PA", Start: 66000000, End: 68899999},
+	{Uf: "AP", Start: 68900000, End: 68999999},
+	{Uf: "AM", Start: 69000000, End: 69299999},
+	{Uf: "RR", Start: 69300000, End: 69399999},
+	{Uf: "AM", Start: 69400000, End: 69899999},
+	{Uf: "AC", Start: 69900000, End: 69999999},
+	{Uf: "DF", Start: 70000000, End: 72799999},
+	{Uf: "GO", Start: 72800000, End: 72999999},
+	{Uf: "DF", Start: 73000000, End: 73699999},
+	{Uf: "GO", Start: 73700000, End: 76799999},
+	{Uf: "RO", Start: 76800000, End: 76999999},
+	{Uf: "TO", Start: 77000000, End: 77999999},
+	{Uf: "MT", Start: 78000000, End: 78899999},
+	{Uf: "MS", Start: 79000000, End: 79999999},
+	{Uf: "PR", Start: 80000000, End: 87999999},
+	{Uf: "SC", Start: 88000000, End: 89999999},
+	{Uf: "RS", Start: 90000000, End: 99999999},
+}
+
+// CepRanges returns a copy of the cep range table, ordered by cep.
+func CepRanges() []CepRange {
+	return append([]CepRange(nil), cepRanges...)
+}
+
+// StateByCep returns the State whose official cep range contains cep.
+// The cep may be written with or without '-'.
+// If the cep is malformed or belongs to no range, it returns an empty State and false.
+func StateByCep(cep string) (State, bool) {
+	if _, err := ValidateCep(cep); err != nil {
+		return State{}, false
+	}
+	n, err := strconv.Atoi(strings.Replace(cep, "-", "", 1))
+	if err != nil {
+		return State{}, false
+	}
+	for _, r := range cepRanges {
+		if n >= r.Start && n <= r.End {
+			return StateByUf(r.Uf)
+		}
+	}
+	return State{}, false
+}
+
+// ValidateCepRange checks if the given cep is valid and belongs to the cep range of some state.
+//
+// A cep like 00000000 has the right shape but is not assigned to any state,
+// so there is no point in asking the providers about it.
+//
+// If the cep is valid, it returns true, nil. Otherwise, it returns false, error.
+func ValidateCepRange(cep string) (bool, error) {
+	if _, err := ValidateCep(cep); err != nil {
+		return false, err
+	}
+	if _, ok := StateByCep(cep); !ok {
+		return false, errors.New("cep does not belong to any state range")
+	}
+	return true, nil
+}
+
+// ValidateCepState checks if the given cep belongs to the cep range of the given uf.
+//
+// If the cep belongs to the uf, it returns true, nil. Otherwise, it returns false, error.
+func ValidateCepState(cep, uf string) (bool, error) {
+	state, ok := StateByCep(cep)
+	if !ok {
+		return false, errors.New("cep does not belong to any state range")
+	}
+	if state.Uf != strings.ToUpper(strings.TrimSpace(uf)) {
+		return false, errors.New("cep belongs to " + state.Uf + ", not to " + uf)
+	}
+	return true, nil
+}
@@ -0,0 +1,101 @@
+package shared
+
+import (
+	"testing"
+)
+
+func TestCepRanges(t *testing.T) {
+	ranges := CepRanges()
+	for i, r := range ranges {
+		if _, ok := StateByUf(r.Uf); !ok {
+			t.Errorf("CepRanges()[%d] has unknown uf %v", i, r.Uf)
+		}
+		if r.Start > r.End {
+			t.Errorf("CepRanges()[%d] starts after it ends: %v", i, r)
+		}
+		if i > 0 && r.Start <= ranges[i-1].End {
+			t.Errorf("CepRanges()[%d] overlaps or precedes the previous range: %v", i, r)
+		}
+	}
+}
+
+func TestStateByCep(t *testing.T) {
+	tests := []struct {
+		name   string
+		cep    string
+		wantUf string
+		wantOk bool
+	}{
+		{name: "state by cep", cep: "39408078", wantUf: "MG", wantOk: true},
+		{name: "state by cep with dash", cep: "39408-078", wantUf: "MG", wantOk: true},
+		{name: "state by cep first sp cep", cep: "01000-000", wantUf: "SP", wantOk: true},
+		{name: "state by cep last rs cep", cep: "99999-999", wantUf: "RS", wantOk: true},
+		{name: "state by cep roraima inside amazonas", cep: "69301-000", wantUf: "RR", wantOk: true},
+		{name: "state by cep second df range", cep: "73010-000", wantUf: "DF", wantOk: true},
+		{name: "state by cep out of range", cep: "00000000", wantUf: "", wantOk: false},
+		{name: "state by cep malformed", cep: "3940807", wantUf: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StateByCep(tt.cep)
+			if ok != tt.wantOk {
+				t.Errorf("StateByCep() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got.Uf != tt.wantUf {
+				t.Errorf("StateByCep() = %v, want uf %v", got, tt.wantUf)
+			}
+		})
+	}
+}
+
+func TestValidateCepRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		cep     string
+		want    bool
+		wantErr bool
+	}{
+		{name: "validate cep range", cep: "39408078", want: true, wantErr: false},
+		{name: "validate cep range out of range", cep: "00000000", want: false, wantErr: true},
+		{name: "validate cep range malformed", cep: "3940807-8", want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateCepRange(tt.cep)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCepRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ValidateCepRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCepState(t *testing.T) {
+	tests := []struct {
+		name    string
+		cep     string
+		uf      string
+		want    bool
+		wantErr bool
+	}{
+		{name: "validate cep state", cep: "39408078", uf: "MG", want: true, wantErr: false},
+		{name: "validate cep state mismatch", cep: "39408078", uf: "SP", want: false, wantErr: true},
+		{name: "validate cep state out of range", cep: "00000000", uf: "SP", want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateCepState(tt.cep, tt.uf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCepState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ValidateCepState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
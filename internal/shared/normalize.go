@@ -0,0 +1,146 @@
+package shared
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold returns s lower-cased, without diacritics and with its spaces trimmed and
+// collapsed, so "São  Paulo" and "sao paulo" fold to the same value.
+// The diacritics are removed by decomposing s in NFD and dropping the marks.
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
+
+// titleAbbreviations maps the folded abbreviations of the titles common in the
+// names of streets, neighborhoods and cities, without the dot, to their folded
+// names, as in "Av. Dr. Silva" or "Sta. Luzia".
+var titleAbbreviations = map[string]string{
+	"dr":    "doutor",
+	"dra":   "doutora",
+	"prof":  "professor",
+	"profa": "professora",
+	"eng":   "engenheiro",
+	"cel":   "coronel",
+	"ten":   "tenente",
+	"sgt":   "sargento",
+	"pres":  "presidente",
+	"gov":   "governador",
+	"dep":   "deputado",
+	"fr":    "frei",
+	"sto":   "santo",
+	"sta":   "santa",
+	"sra":   "senhora",
+	"jd":    "jardim",
+	"jdm":   "jardim",
+}
+
+// dottedTitleAbbreviations are the abbreviations of titles that are also letters
+// or words, as "pe" in "Rua Pé de Serra" and "ver" in "Rua Ver o Peso", so they
+// are written in full only with their dot.
+var dottedTitleAbbreviations = map[string]string{
+	"gen": "general",
+	"mal": "marechal",
+	"cap": "capitao",
+	"des": "desembargador",
+	"ver": "vereador",
+	"min": "ministro",
+	"pe":  "padre",
+	"d":   "dom",
+	"s":   "sao",
+	"n":   "nossa",
+}
+
+// NormalizeAddress returns s folded, with punctuation turned into spaces and the
+// abbreviations written in full: the street type at the start, as in "R." for
+// "rua", and the titles anywhere, as in "Dr." for "doutor" and "Sta." for "santa".
+// A title is written in full only with its dot or before another word, so "Dr"
+// alone stays a word, and the titles that are also letters or words, as "D." for
+// "dom" or "Pe." for "padre", only with their dot.
+// Two parts of an address that normalize to the same value are the same place.
+func NormalizeAddress(s string) string {
+	words, dotted := addressWords(s)
+	for i, w := range words {
+		if i == 0 && len(words) > 1 {
+			if st, ok := streetTypeByWord(w); ok {
+				words[i] = Fold(st.Name)
+				continue
+			}
+		}
+		if full, ok := titleAbbreviations[w]; ok && (dotted[i] || i < len(words)-1) {
+			words[i] = full
+		} else if full, ok := dottedTitleAbbreviations[w]; ok && dotted[i] {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// addressWords splits the folded s into words at spaces and punctuation, other
+// than hyphens and slashes, and tells which words were followed by a dot.
+func addressWords(s string) (words []string, dotted []bool) {
+	fields := strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '-' && r != '/' && r != '.')
+	})
+	for _, field := range fields {
+		parts := strings.Split(field, ".")
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			words = append(words, part)
+			dotted = append(dotted, i < len(parts)-1)
+		}
+	}
+	return words, dotted
+}
+
+// EqualAddress reports whether a and b are the same part of an address, ignoring
+// case, accents, spaces, punctuation and abbreviations, so "R. Sta. Luzia" equals
+// "Rua Santa Luzia".
+func EqualAddress(a, b string) bool {
+	return NormalizeAddress(a) == NormalizeAddress(b)
+}
+
+// Similarity returns how alike a and b are, from 0 to 1, once normalized by
+// NormalizeAddress: 1 minus their edit distance over the length of the longer
+// one. Equal addresses score 1; "Montes Claros" and "Montes Caros" score 0.92.
+func Similarity(a, b string) float64 {
+	ra, rb := []rune(NormalizeAddress(a)), []rune(NormalizeAddress(b))
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the number of insertions, deletions and substitutions that
+// turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
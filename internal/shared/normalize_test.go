@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "São Paulo", want: "sao paulo"},
+		{s: "  ESPÍRITO \t SANTO ", want: "espirito santo"},
+		{s: "Pç. da Sé", want: "pc. da se"},
+		{s: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := Fold(tt.s); got != tt.want {
+				t.Errorf("Fold() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "R. Sta. Luzia", want: "rua santa luzia"},
+		{s: "Av. Dr. Herlindo Silveira", want: "avenida doutor herlindo silveira"},
+		{s: "Pça.  N. Sra. Aparecida", want: "praca nossa senhora aparecida"},
+		{s: "Rodovia BR-116, km 12", want: "rodovia br-116 km 12"},
+		{s: "R", want: "r"},
+		{s: "Rua D", want: "rua d"},
+		{s: "Rua D.", want: "rua dom"},
+		{s: "Rua D Pedro II", want: "rua d pedro ii"},
+		{s: "Rua D. Pedro II", want: "rua dom pedro ii"},
+		{s: "Av Dr Silva", want: "avenida doutor silva"},
+		{s: "Rua Dr", want: "rua dr"},
+		{s: "R.S.Bento", want: "rua sao bento"},
+		{s: "Ibituruna", want: "ibituruna"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := NormalizeAddress(tt.s); got != tt.want {
+				t.Errorf("NormalizeAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAddress(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "Sao Paulo", b: "São Paulo", want: true},
+		{a: "R. Sta. Luzia", b: "Rua Santa Luzia", want: true},
+		{a: "AV. PRES. VARGAS", b: "Avenida Presidente Vargas", want: true},
+		{a: "Rua Santa Luzia", b: "Rua Santo Luzia", want: false},
+		{a: "Montes Claros", b: "Montes Caros", want: false},
+		{a: "Rua S. Bento", b: "Rua São Bento", want: true},
+		{a: "Rua D", b: "Rua Dom", want: false},
+		{a: "Rua S", b: "Rua São", want: false},
+		{a: "Quadra N", b: "Quadra Nossa", want: false},
+		{a: "Rua Ver", b: "Rua Vereador", want: false},
+		{a: "Quadra Min", b: "Quadra Ministro", want: false},
+		{a: "Rua Pé de Serra", b: "Rua Padre de Serra", want: false},
+		{a: "Rua Ver o Peso", b: "Rua Vereador o Peso", want: false},
+		{a: "Quadra N 3", b: "Quadra Nossa 3", want: false},
+		{a: "Rua Pe. Anchieta", b: "Rua Padre Anchieta", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"="+tt.b, func(t *testing.T) {
+			if got := EqualAddress(tt.a, tt.b); got != tt.want {
+				t.Errorf("EqualAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{a: "R. Sta. Luzia", b: "Rua Santa Luzia", want: 1},
+		{a: "Montes Claros", b: "Montes Caros", want: 0.923},
+		{a: "Ibituruna", b: "Centro", want: 0.222},
+		{a: "", b: "", want: 1},
+		{a: "abc", b: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"~"+tt.b, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Similarity() = %.3f, want %.3f", got, tt.want)
+			}
+		})
+	}
+}
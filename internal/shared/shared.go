@@ -3,21 +3,8 @@ package shared
 import (
 	"errors"
 	"regexp"
-
-	"golang.org/x/exp/slices"
 )
 
-var states_short = []string{"AC", "AL", "AP", "AM", "BA", "CE", "DF", "ES", "GO", "MA", "MT", "MS", "MG", "PA", "PB", "PR", "PE", "PI", "RJ", "RN", "RS", "RO", "RR", "SC", "SP", "SE", "TO"}
-
-var states_long = []string{"Acre", "Alagoas",
-	"Amapá", "Amazonas", "Bahia", "Ceará", "Distrito Federal",
-	"Espirito Santo", "Goiás", "Maranhão", "Mato Grosso", "Mato Grosso do Sul",
-	"Minas Gerais", "Paraí", "Pará", "Paraná", "Pernambuco", "Piaú", "Rio de Janeiro",
-	"Rio Grande do Norte", "Rio Grande do Sul", "Rondônia", "Roraima", "Santa Catarina",
-	"São Paulo", "Sergipe", "Tocantins"}
-
-var regioes = []string{"Sul", "Sudeste", "Centro-Oeste", "Norte", "Nordeste"}
-
 // ValidateCep checks if the given cep is valid.
 //
 // A valid cep must have 8 digits, optionally with '-'.
@@ -67,25 +54,29 @@ func ValidateCepWithoutDash(cep string) (bool, error) {
 //
 // If the state abbreviation is valid, it returns true. Otherwise, it returns false.
 func ValidateStateShort(state string) bool {
-	return slices.Contains(states_short, state)
+	_, ok := StateByUf(state)
+	return ok
 }
 
 // ValidateStateLong checks if the given state name is valid.
 //
 // A valid state name must be one of the recognized Brazilian state names,
-// such as "Acre" or "São Paulo".
+// such as "Acre" or "São Paulo". Case, accents, extra spaces and abbreviations,
+// as in "S. Paulo", are ignored.
 //
 // If the state name is valid, it returns true. Otherwise, it returns false.
 func ValidateStateLong(state string) bool {
-	return slices.Contains(states_long, state)
+	_, ok := StateByName(state)
+	return ok
 }
 
 // ValidateRegiao checks if the given region name is valid.
 //
 // A valid region name must be one of the recognized Brazilian region names,
-// such as "Sul" or "Nordeste".
+// such as "Sul" or "Nordeste". Case and accents are ignored.
 //
 // If the region name is valid, it returns true. Otherwise, it returns false.
 func ValidateRegiao(regiao string) bool {
-	return slices.Contains(regioes, regiao)
+	_, ok := RegionByName(regiao)
+	return ok
 }
@@ -60,6 +60,48 @@ func TestValidateStateLong(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "validate state long paraiba",
+			args: args{
+				state: "Paraíba",
+			},
+			want: true,
+		},
+		{
+			name: "validate state long piaui",
+			args: args{
+				state: "Piauí",
+			},
+			want: true,
+		},
+		{
+			name: "validate state long espirito santo",
+			args: args{
+				state: "Espírito Santo",
+			},
+			want: true,
+		},
+		{
+			name: "validate state long without accents",
+			args: args{
+				state: "sao paulo",
+			},
+			want: true,
+		},
+		{
+			name: "validate state long with extra spaces",
+			args: args{
+				state: " Rio  Grande do   Sul ",
+			},
+			want: true,
+		},
+		{
+			name: "validate state long abbreviated",
+			args: args{
+				state: "Sta. Catarina",
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
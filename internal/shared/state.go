@@ -0,0 +1,118 @@
+package shared
+
+import "strings"
+
+// Region is one of the five Brazilian geographic regions.
+type Region string
+
+const (
+	RegionNorte       Region = "Norte"
+	RegionNordeste    Region = "Nordeste"
+	RegionCentroOeste Region = "Centro-Oeste"
+	RegionSudeste     Region = "Sudeste"
+	RegionSul         Region = "Sul"
+)
+
+var regions = []Region{RegionNorte, RegionNordeste, RegionCentroOeste, RegionSudeste, RegionSul}
+
+// State links a Brazilian federative unit to its UF code, full name,
+// geographic region and IBGE state code.
+type State struct {
+	Uf       string
+	Name     string
+	Region   Region
+	IbgeCode string
+}
+
+var states = []State{
+	{Uf: "RO", Name: "Rondônia", Region: RegionNorte, IbgeCode: "11"},
+	{Uf: "AC", Name: "Acre", Region: RegionNorte, IbgeCode: "12"},
+	{Uf: "AM", Name: "Amazonas", Region: RegionNorte, IbgeCode: "13"},
+	{Uf: "RR", Name: "Roraima", Region: RegionNorte, IbgeCode: "14"},
+	{Uf: "PA", Name: "Pará", Region: RegionNorte, IbgeCode: "15"},
+	{Uf: "AP", Name: "Amapá", Region: RegionNorte, IbgeCode: "16"},
+	{Uf: "TO", Name: "Tocantins", Region: RegionNorte, IbgeCode: "17"},
+	{Uf: "MA", Name: "Maranhão", Region: RegionNordeste, IbgeCode: "21"},
+	{Uf: "PI", Name: "Piauí", Region: RegionNordeste, IbgeCode: "22"},
+	{Uf: "CE", Name: "Ceará", Region: RegionNordeste, IbgeCode: "23"},
+	{Uf: "RN", Name: "Rio Grande do Norte", Region: RegionNordeste, IbgeCode: "24"},
+	{Uf: "PB", Name: "Paraíba", Region: RegionNordeste, IbgeCode: "25"},
+	{Uf: "PE", Name: "Pernambuco", Region: RegionNordeste, IbgeCode: "26"},
+	{Uf: "AL", Name: "Alagoas", Region: RegionNordeste, IbgeCode: "27"},
+	{Uf: "SE", Name: "Sergipe", Region: RegionNordeste, IbgeCode: "28"},
+	{Uf: "BA", Name: "Bahia", Region: RegionNordeste, IbgeCode: "29"},
+	{Uf: "MG", Name: "Minas Gerais", Region: RegionSudeste, IbgeCode: "31"},
+	{Uf: "ES", Name: "Espírito Santo", Region: RegionSudeste, IbgeCode: "32"},
+	{Uf: "RJ", Name: "Rio de Janeiro", Region: RegionSudeste, IbgeCode: "33"},
+	{Uf: "SP", Name: "São Paulo", Region: RegionSudeste, IbgeCode: "35"},
+	{Uf: "PR", Name: "Paraná", Region: RegionSul, IbgeCode: "41"},
+	{Uf: "SC", Name: "Santa Catarina", Region: RegionSul, IbgeCode: "42"},
+	{Uf: "RS", Name: "Rio Grande do Sul", Region: RegionSul, IbgeCode: "43"},
+	{Uf: "MS", Name: "Mato Grosso do Sul", Region: RegionCentroOeste, IbgeCode: "50"},
+	{Uf: "MT", Name: "Mato Grosso", Region: RegionCentroOeste, IbgeCode: "51"},
+	{Uf: "GO", Name: "Goiás", Region: RegionCentroOeste, IbgeCode: "52"},
+	{Uf: "DF", Name: "Distrito Federal", Region: RegionCentroOeste, IbgeCode: "53"},
+}
+
+// States returns a copy of the reference list of the 27 Brazilian federative units,
+// ordered by IBGE state code.
+func States() []State {
+	return append([]State(nil), states...)
+}
+
+// Regions returns a copy of the reference list of the five Brazilian regions.
+func Regions() []Region {
+	return append([]Region(nil), regions...)
+}
+
+// StateByUf returns the State whose UF code is uf, such as "MG".
+// The comparison ignores case and surrounding spaces.
+// If no state has the given code, it returns an empty State and false.
+func StateByUf(uf string) (State, bool) {
+	uf = strings.ToUpper(strings.TrimSpace(uf))
+	for _, s := range states {
+		if s.Uf == uf {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// StateByName returns the State whose full name is name, such as "Espírito Santo".
+// The comparison ignores case, accents, spaces and abbreviations, as EqualAddress
+// does, so "espirito  santo", "ESPÍRITO SANTO" and "Sta. Catarina" also match.
+// If no state has the given name, it returns an empty State and false.
+func StateByName(name string) (State, bool) {
+	name = NormalizeAddress(name)
+	for _, s := range states {
+		if NormalizeAddress(s.Name) == name {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// StateByIbgeCode returns the State whose two-digit IBGE code is code, such as "31".
+// If no state has the given code, it returns an empty State and false.
+func StateByIbgeCode(code string) (State, bool) {
+	code = strings.TrimSpace(code)
+	for _, s := range states {
+		if s.IbgeCode == code {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+// RegionByName returns the Region whose name is name, such as "Centro-Oeste".
+// The comparison ignores case, accents and surrounding spaces.
+// If no region has the given name, it returns an empty Region and false.
+func RegionByName(name string) (Region, bool) {
+	name = Fold(name)
+	for _, r := range regions {
+		if Fold(string(r)) == name {
+			return r, true
+		}
+	}
+	return "", false
+}
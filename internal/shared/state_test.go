@@ -0,0 +1,133 @@
+package shared
+
+import (
+	"testing"
+)
+
+func TestStates(t *testing.T) {
+	got := States()
+	if len(got) != 27 {
+		t.Fatalf("States() returned %d states, want 27", len(got))
+	}
+	got[0].Uf = "XX"
+	if s, _ := StateByIbgeCode("11"); s.Uf != "RO" {
+		t.Errorf("States() must return a copy, reference data was changed to %v", s)
+	}
+}
+
+func TestStateByUf(t *testing.T) {
+	tests := []struct {
+		name   string
+		uf     string
+		want   State
+		wantOk bool
+	}{
+		{
+			name:   "state by uf",
+			uf:     "ES",
+			want:   State{Uf: "ES", Name: "Espírito Santo", Region: RegionSudeste, IbgeCode: "32"},
+			wantOk: true,
+		},
+		{
+			name:   "state by lower case uf",
+			uf:     "pb",
+			want:   State{Uf: "PB", Name: "Paraíba", Region: RegionNordeste, IbgeCode: "25"},
+			wantOk: true,
+		},
+		{
+			name:   "state by uf error",
+			uf:     "MM",
+			want:   State{},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StateByUf(tt.uf)
+			if ok != tt.wantOk {
+				t.Errorf("StateByUf() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("StateByUf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  string
+		wantUf string
+		wantOk bool
+	}{
+		{name: "state by name", state: "Piauí", wantUf: "PI", wantOk: true},
+		{name: "state by name without accents", state: "Piaui", wantUf: "PI", wantOk: true},
+		{name: "state by name upper case", state: "ESPÍRITO SANTO", wantUf: "ES", wantOk: true},
+		{name: "state by name with spaces", state: " Paraíba ", wantUf: "PB", wantOk: true},
+		{name: "state by name para is not paraiba", state: "Para", wantUf: "PA", wantOk: true},
+		{name: "state by name error", state: "Paraí", wantUf: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StateByName(tt.state)
+			if ok != tt.wantOk {
+				t.Errorf("StateByName() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got.Uf != tt.wantUf {
+				t.Errorf("StateByName() = %v, want uf %v", got, tt.wantUf)
+			}
+		})
+	}
+}
+
+func TestStateByIbgeCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		wantUf string
+		wantOk bool
+	}{
+		{name: "state by ibge code", code: "31", wantUf: "MG", wantOk: true},
+		{name: "state by ibge code error", code: "99", wantUf: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StateByIbgeCode(tt.code)
+			if ok != tt.wantOk {
+				t.Errorf("StateByIbgeCode() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got.Uf != tt.wantUf {
+				t.Errorf("StateByIbgeCode() = %v, want uf %v", got, tt.wantUf)
+			}
+		})
+	}
+}
+
+func TestRegionByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		want   Region
+		wantOk bool
+	}{
+		{name: "region by name", region: "Centro-Oeste", want: RegionCentroOeste, wantOk: true},
+		{name: "region by name lower case", region: "nordeste", want: RegionNordeste, wantOk: true},
+		{name: "region by name error", region: "Sudoeste", want: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RegionByName(tt.region)
+			if ok != tt.wantOk {
+				t.Errorf("RegionByName() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RegionByName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
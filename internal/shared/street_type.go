@@ -0,0 +1,112 @@
+package shared
+
+import "strings"
+
+// StreetType is a type of logradouro, such as "Avenida", with the abbreviation the
+// Correios use for it, such as "Av.".
+type StreetType struct {
+	Name         string
+	Abbreviation string
+}
+
+var streetTypes = []StreetType{
+	{Name: "Rua", Abbreviation: "R."},
+	{Name: "Avenida", Abbreviation: "Av."},
+	{Name: "Travessa", Abbreviation: "Tv."},
+	{Name: "Alameda", Abbreviation: "Al."},
+	{Name: "Praça", Abbreviation: "Pç."},
+	{Name: "Rodovia", Abbreviation: "Rod."},
+	{Name: "Estrada", Abbreviation: "Estr."},
+	{Name: "Largo", Abbreviation: "Lg."},
+	{Name: "Viela", Abbreviation: "Vla."},
+	{Name: "Beco", Abbreviation: "Bc."},
+	{Name: "Ladeira", Abbreviation: "Ld."},
+	{Name: "Vila", Abbreviation: "Vl."},
+	{Name: "Quadra", Abbreviation: "Qd."},
+	{Name: "Conjunto", Abbreviation: "Cj."},
+	{Name: "Parque", Abbreviation: "Pq."},
+	{Name: "Servidão", Abbreviation: "Svd."},
+}
+
+// StreetTypes returns a copy of the reference list of street types.
+func StreetTypes() []StreetType {
+	return append([]StreetType(nil), streetTypes...)
+}
+
+// StreetTypeByName returns the StreetType whose name is name, such as "avenida".
+// The comparison ignores case, accents and surrounding spaces.
+// If no street type has the given name, it returns an empty StreetType and false.
+func StreetTypeByName(name string) (StreetType, bool) {
+	name = Fold(name)
+	for _, st := range streetTypes {
+		if Fold(st.Name) == name {
+			return st, true
+		}
+	}
+	return StreetType{}, false
+}
+
+// streetTypeAliases maps other folded spellings of the street types found in the
+// answers of the providers, without the final dot, to their names. The names and
+// the abbreviations of StreetTypes are recognized as well.
+var streetTypeAliases = map[string]string{
+	"avd":   "Avenida",
+	"avda":  "Avenida",
+	"trav":  "Travessa",
+	"pca":   "Praça",
+	"est":   "Estrada",
+	"lgo":   "Largo",
+	"lad":   "Ladeira",
+	"conj":  "Conjunto",
+	"serv":  "Servidão",
+	"servd": "Servidão",
+}
+
+// ParseStreet splits a logradouro, such as "Avenida Herlindo Silveira" or
+// "R. das Flores", into its StreetType and its name, "Herlindo Silveira" or
+// "das Flores". The type may be written in full or abbreviated, with or without
+// the dot, in any case and with or without accents. Spaces in the name are
+// collapsed.
+// If street does not start with a known type followed by a name, it returns an
+// empty StreetType, street with its spaces collapsed, and false.
+func ParseStreet(street string) (StreetType, string, bool) {
+	words := strings.Fields(street)
+	if len(words) == 0 {
+		return StreetType{}, "", false
+	}
+	if st, ok := streetTypeByWord(words[0]); ok && len(words) > 1 {
+		return st, strings.Join(words[1:], " "), true
+	}
+	// "R.das Flores" has no space after the abbreviation.
+	if first, rest, ok := strings.Cut(words[0], "."); ok && rest != "" {
+		if st, ok := streetTypeByWord(first); ok {
+			return st, strings.Join(append([]string{rest}, words[1:]...), " "), true
+		}
+	}
+	return StreetType{}, strings.Join(words, " "), false
+}
+
+// streetTypeByWord returns the StreetType named or abbreviated by word.
+func streetTypeByWord(word string) (StreetType, bool) {
+	word = strings.TrimSuffix(Fold(word), ".")
+	if name, ok := streetTypeAliases[word]; ok {
+		word = Fold(name)
+	}
+	for _, st := range streetTypes {
+		if Fold(st.Name) == word || strings.TrimSuffix(Fold(st.Abbreviation), ".") == word {
+			return st, true
+		}
+	}
+	return StreetType{}, false
+}
+
+// NormalizeStreet returns street with its type written in full, as in
+// "Rua das Flores" for "R. das Flores", and its spaces collapsed.
+// A street without a known type is returned with its spaces collapsed.
+func NormalizeStreet(street string) string {
+	st, name, ok := ParseStreet(street)
+	if !ok {
+		return name
+	}
+	return st.Name + " " + name
+}
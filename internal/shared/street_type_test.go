@@ -0,0 +1,71 @@
+package shared
+
+import "testing"
+
+func TestStreetTypeByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   StreetType
+		wantOk bool
+	}{
+		{name: "Avenida", want: StreetType{Name: "Avenida", Abbreviation: "Av."}, wantOk: true},
+		{name: " PRACA ", want: StreetType{Name: "Praça", Abbreviation: "Pç."}, wantOk: true},
+		{name: "Av.", wantOk: false},
+		{name: "Herlindo", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := StreetTypeByName(tt.name)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("StreetTypeByName() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseStreet(t *testing.T) {
+	tests := []struct {
+		street   string
+		wantType string
+		wantName string
+		wantOk   bool
+	}{
+		{street: "Avenida Herlindo Silveira", wantType: "Avenida", wantName: "Herlindo Silveira", wantOk: true},
+		{street: "R. das Flores", wantType: "Rua", wantName: "das Flores", wantOk: true},
+		{street: "R.das Flores", wantType: "Rua", wantName: "das Flores", wantOk: true},
+		{street: "AV  Paulista", wantType: "Avenida", wantName: "Paulista", wantOk: true},
+		{street: "Pça. da Sé", wantType: "Praça", wantName: "da Sé", wantOk: true},
+		{street: "praca da Se", wantType: "Praça", wantName: "da Se", wantOk: true},
+		{street: "Trav. Um", wantType: "Travessa", wantName: "Um", wantOk: true},
+		{street: "Rod. BR-116", wantType: "Rodovia", wantName: "BR-116", wantOk: true},
+		{street: "Dr.Silva  Jardim", wantName: "Dr.Silva Jardim", wantOk: false},
+		{street: "Rua", wantName: "Rua", wantOk: false},
+		{street: "  ", wantName: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.street, func(t *testing.T) {
+			st, name, ok := ParseStreet(tt.street)
+			if st.Name != tt.wantType || name != tt.wantName || ok != tt.wantOk {
+				t.Errorf("ParseStreet() = %q, %q, %v, want %q, %q, %v", st.Name, name, ok, tt.wantType, tt.wantName, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestNormalizeStreet(t *testing.T) {
+	tests := []struct {
+		street string
+		want   string
+	}{
+		{street: "R. das Flores", want: "Rua das Flores"},
+		{street: "av. herlindo  silveira", want: "Avenida herlindo silveira"},
+		{street: "Dr. Silva Jardim", want: "Dr. Silva Jardim"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.street, func(t *testing.T) {
+			if got := NormalizeStreet(tt.street); got != tt.want {
+				t.Errorf("NormalizeStreet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// Index is a prefix index of ceps, for autocomplete. The ceps are kept sorted by
+// their 8 digits, so the ceps starting with a prefix are a contiguous run, found
+// by binary search in O(log n). An Index is read-only after New and safe for
+// concurrent use.
+type Index struct {
+	keys []string
+	ceps []dto.Cep
+}
+
+// New indexes the ceps of the sources, such as the dataset and the cache. A cep
+// in more than one source is indexed once, as found in the first one.
+func New(sources ...[]dto.Cep) *Index {
+	n := 0
+	for _, src := range sources {
+		n += len(src)
+	}
+	x := &Index{keys: make([]string, 0, n), ceps: make([]dto.Cep, 0, n)}
+	seen := make(map[string]bool, n)
+	for _, src := range sources {
+		for _, c := range src {
+			k := key(c.Cep)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			x.keys = append(x.keys, k)
+			x.ceps = append(x.ceps, c)
+		}
+	}
+	sort.Sort(byKey{x})
+	return x
+}
+
+// Suggest returns the ceps starting with prefix, sorted, up to limit ceps; a limit
+// of 0 or less returns all of them. The prefix is made of digits, as typed by a
+// user, with or without the dash, as in "39408-0". An empty prefix matches every cep.
+// It returns nil if the prefix is not valid, as told by ValidPrefix.
+func (x *Index) Suggest(prefix string, limit int) []dto.Cep {
+	if !ValidPrefix(prefix) {
+		return nil
+	}
+	prefix = key(prefix)
+	start := sort.SearchStrings(x.keys, prefix)
+	end := start
+	for end < len(x.keys) && strings.HasPrefix(x.keys[end], prefix) {
+		if limit > 0 && end-start == limit {
+			break
+		}
+		end++
+	}
+	if end == start {
+		return nil
+	}
+	return append([]dto.Cep(nil), x.ceps[start:end]...)
+}
+
+// Len returns the number of ceps indexed.
+func (x *Index) Len() int {
+	return len(x.keys)
+}
+
+// key returns the digits of a cep, without spaces around and without the dash.
+func key(cep string) string {
+	return strings.Replace(strings.TrimSpace(cep), "-", "", 1)
+}
+
+// ValidPrefix reports whether prefix may start a cep: up to 8 digits, with or
+// without the dash.
+func ValidPrefix(prefix string) bool {
+	prefix = key(prefix)
+	if len(prefix) > 8 {
+		return false
+	}
+	for _, r := range prefix {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// byKey sorts the ceps of an index by key.
+type byKey struct {
+	x *Index
+}
+
+func (b byKey) Len() int           { return len(b.x.keys) }
+func (b byKey) Less(i, j int) bool { return b.x.keys[i] < b.x.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.x.keys[i], b.x.keys[j] = b.x.keys[j], b.x.keys[i]
+	b.x.ceps[i], b.x.ceps[j] = b.x.ceps[j], b.x.ceps[i]
+}
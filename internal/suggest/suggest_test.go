@@ -0,0 +1,91 @@
+package suggest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func ceps(suggested []dto.Cep) []string {
+	var got []string
+	for _, c := range suggested {
+		got = append(got, c.Cep)
+	}
+	return got
+}
+
+func TestIndex_Suggest(t *testing.T) {
+	dataset := []dto.Cep{
+		{Cep: "39408078", City: "Montes Claros"},
+		{Cep: "01001000", City: "São Paulo"},
+		{Cep: "39400000", City: "Montes Claros"},
+		{Cep: "39408001", City: "Montes Claros"},
+	}
+	cache := []dto.Cep{
+		{Cep: "39408-078", City: "Montes Claros (cache)"},
+		{Cep: "39270000", City: "Lassance"},
+	}
+	x := New(dataset, cache)
+	if x.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", x.Len())
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{name: "prefix", prefix: "394", want: []string{"39400000", "39408001", "39408078"}},
+		{name: "prefix with dash", prefix: "39408-0", want: []string{"39408001", "39408078"}},
+		{name: "limit", prefix: "3", limit: 2, want: []string{"39270000", "39400000"}},
+		{name: "whole cep", prefix: "01001000", want: []string{"01001000"}},
+		{name: "empty prefix", prefix: "", limit: 1, want: []string{"01001000"}},
+		{name: "no match", prefix: "9", want: nil},
+		{name: "not digits", prefix: "39a", want: nil},
+		{name: "too long", prefix: "394080780", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ceps(x.Suggest(tt.prefix, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q, %d) = %v, want %v", tt.prefix, tt.limit, got, tt.want)
+			}
+		})
+	}
+	if got := x.Suggest("39408078", 0); got[0].City != "Montes Claros" {
+		t.Errorf("Suggest() = %v, want the cep of the first source", got)
+	}
+}
+
+// million returns a million ceps, from 10000000 to 10999999.
+func million() []dto.Cep {
+	all := make([]dto.Cep, 1_000_000)
+	for i := range all {
+		all[i] = dto.Cep{Cep: fmt.Sprintf("%08d", 10_000_000+i), State: "SP", City: "São Paulo"}
+	}
+	return all
+}
+
+func BenchmarkNew(b *testing.B) {
+	all := million()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		New(all)
+	}
+}
+
+func BenchmarkIndex_Suggest(b *testing.B) {
+	x := New(million())
+	for _, prefix := range []string{"1", "1054", "105432", "10543210"} {
+		b.Run(prefix, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if len(x.Suggest(prefix, 10)) == 0 {
+					b.Fatal("no suggestion")
+				}
+			}
+		})
+	}
+}
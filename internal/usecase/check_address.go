@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
+)
+
+// Verdicts of a FieldCheck.
+const (
+	// VerdictMatch means the field is the one of the cep, up to case, accents,
+	// spaces and abbreviations.
+	VerdictMatch = "match"
+	// VerdictFuzzyMatch means the field is close to the one of the cep, such as a
+	// typo, with a similarity of at least FuzzyThreshold. Fields that differ in a
+	// number or a single letter, as "Quadra 3" and "Quadra 4", are never close.
+	VerdictFuzzyMatch = "fuzzy_match"
+	// VerdictMismatch means the field is not the one of the cep, or is missing.
+	VerdictMismatch = "mismatch"
+	// VerdictUnknown means the cep does not tell the field, as the street and the
+	// neighborhood of a city-level cep.
+	VerdictUnknown = "unknown"
+)
+
+// FuzzyThreshold is the least shared.Similarity of a VerdictFuzzyMatch.
+const FuzzyThreshold = 0.8
+
+// Address is an address typed by a user, such as in a checkout form.
+type Address struct {
+	Cep          string `json:"cep"`
+	Street       string `json:"street"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	// State is the UF, such as "MG", or the name of the state.
+	State string `json:"state"`
+}
+
+// FieldCheck is the verdict on one field of an Address: what was typed, what the
+// cep has and how alike they are, from 0 to 1.
+type FieldCheck struct {
+	Field      string  `json:"field"`
+	Input      string  `json:"input"`
+	Expected   string  `json:"expected"`
+	Verdict    string  `json:"verdict"`
+	Similarity float64 `json:"similarity"`
+}
+
+// AddressCheck is the outcome of CheckAddress: the cep resolved by the race, the
+// verdict on each field, whether none is a mismatch, and the address corrected
+// with the fields of the cep.
+type AddressCheck struct {
+	Result    Result       `json:"result"`
+	Fields    []FieldCheck `json:"fields"`
+	Valid     bool         `json:"valid"`
+	Suggested Address      `json:"suggested"`
+}
+
+// CheckAddress resolves the cep of addr with Lookup and compares each field of addr
+// with the cep. The street may be typed with or without its type, so "Herlindo
+// Silveira" matches "Avenida Herlindo Silveira". It returns the errors of Lookup.
+func CheckAddress(ctx context.Context, addr Address, opts RaceOptions) (AddressCheck, error) {
+	result, err := Lookup(ctx, addr.Cep, opts)
+	if err != nil {
+		return AddressCheck{}, err
+	}
+	return checkAddress(addr, result), nil
+}
+
+// checkAddress compares addr with the cep of result.
+func checkAddress(addr Address, result Result) AddressCheck {
+	cep := result.Cep
+	if cep.StreetType == "" && cep.StreetName == "" {
+		cep.ParseStreet()
+	}
+	check := AddressCheck{
+		Result: result,
+		Fields: []FieldCheck{
+			checkField("street", addr.Street, cep.Street, cep.StreetName),
+			checkField("neighborhood", addr.Neighborhood, cep.Neighborhood),
+			checkField("city", addr.City, cep.City),
+			checkState(addr.State, cep.State),
+		},
+		Valid: true,
+		Suggested: Address{
+			Cep:          dto.FormatCep(cep.Cep),
+			Street:       cep.Street,
+			Neighborhood: cep.Neighborhood,
+			City:         cep.City,
+			State:        cep.State,
+		},
+	}
+	for _, f := range check.Fields {
+		if f.Verdict == VerdictMismatch {
+			check.Valid = false
+		}
+	}
+	// A city-level cep does not tell the street and the neighborhood, so the
+	// suggestion keeps the ones typed.
+	if cep.Street == "" {
+		check.Suggested.Street = addr.Street
+	}
+	if cep.Neighborhood == "" {
+		check.Suggested.Neighborhood = addr.Neighborhood
+	}
+	return check
+}
+
+// checkField compares the input with the expected value of a field, or with any
+// of the alternatives, keeping the closest. An alternative is a fuzzy match only
+// if it has the same markers as the input.
+func checkField(field, input, expected string, alternatives ...string) FieldCheck {
+	f := FieldCheck{Field: field, Input: input, Expected: expected, Verdict: VerdictMismatch}
+	if expected == "" {
+		f.Verdict = VerdictUnknown
+		return f
+	}
+	if strings.TrimSpace(input) == "" {
+		return f
+	}
+	for _, candidate := range append([]string{expected}, alternatives...) {
+		if candidate == "" {
+			continue
+		}
+		if shared.EqualAddress(input, candidate) {
+			f.Verdict, f.Similarity = VerdictMatch, 1
+			return f
+		}
+		similarity := shared.Similarity(input, candidate)
+		f.Similarity = max(f.Similarity, similarity)
+		if similarity >= FuzzyThreshold && slices.Equal(markers(input), markers(candidate)) {
+			f.Verdict = VerdictFuzzyMatch
+		}
+	}
+	return f
+}
+
+// markers returns the words of the normalized s that tell one place from its
+// neighbors rather than spell a name: the ones with a digit, as "3" in
+// "Quadra 3" or "br-116", and the single letters, as "a" in "Rua A". One edit
+// in a marker is another place, not a typo.
+func markers(s string) []string {
+	var words []string
+	for _, w := range strings.Fields(shared.NormalizeAddress(s)) {
+		if utf8.RuneCountInString(w) == 1 || strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// checkState compares the input, a UF or a state name, with the UF of the cep.
+// A state is either right or wrong, so there is no fuzzy match.
+func checkState(input, expected string) FieldCheck {
+	f := FieldCheck{Field: "state", Input: input, Expected: expected, Verdict: VerdictMismatch}
+	state, ok := shared.StateByUf(input)
+	if !ok {
+		state, ok = shared.StateByName(input)
+	}
+	if ok && strings.EqualFold(state.Uf, expected) {
+		f.Verdict, f.Similarity = VerdictMatch, 1
+	}
+	return f
+}
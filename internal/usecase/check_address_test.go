@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func TestCheckAddress(t *testing.T) {
+	streetLevel := Result{Cep: dto.Cep{Cep: "39408078", State: "MG", City: "Montes Claros", Neighborhood: "Ibituruna", Street: "Avenida Herlindo Silveira"}}
+	cityLevel := Result{Cep: dto.Cep{Cep: "39270000", State: "MG", City: "Lassance", CityLevel: true}}
+	tests := []struct {
+		name          string
+		addr          Address
+		result        Result
+		wantVerdicts  []string
+		wantValid     bool
+		wantSuggested Address
+	}{
+		{
+			name:          "typed differently",
+			addr:          Address{Cep: "39408-078", Street: "Av. Herlindo Silveira", Neighborhood: "IBITURUNA", City: "montes  claros", State: "Minas Gerais"},
+			result:        streetLevel,
+			wantVerdicts:  []string{VerdictMatch, VerdictMatch, VerdictMatch, VerdictMatch},
+			wantValid:     true,
+			wantSuggested: Address{Cep: "39408-078", Street: "Avenida Herlindo Silveira", Neighborhood: "Ibituruna", City: "Montes Claros", State: "MG"},
+		},
+		{
+			name:          "street without type and typos",
+			addr:          Address{Cep: "39408078", Street: "Herlindo Silveira", Neighborhood: "Ibiturna", City: "Montes Caros", State: "mg"},
+			result:        streetLevel,
+			wantVerdicts:  []string{VerdictMatch, VerdictFuzzyMatch, VerdictFuzzyMatch, VerdictMatch},
+			wantValid:     true,
+			wantSuggested: Address{Cep: "39408-078", Street: "Avenida Herlindo Silveira", Neighborhood: "Ibituruna", City: "Montes Claros", State: "MG"},
+		},
+		{
+			name:          "wrong fields",
+			addr:          Address{Cep: "39408078", Street: "Rua das Flores", City: "Montes Claros", State: "SP"},
+			result:        streetLevel,
+			wantVerdicts:  []string{VerdictMismatch, VerdictMismatch, VerdictMatch, VerdictMismatch},
+			wantValid:     false,
+			wantSuggested: Address{Cep: "39408-078", Street: "Avenida Herlindo Silveira", Neighborhood: "Ibituruna", City: "Montes Claros", State: "MG"},
+		},
+		{
+			name:          "city level cep",
+			addr:          Address{Cep: "39270000", Street: "Rua Um", Neighborhood: "Centro", City: "Lassance", State: "MG"},
+			result:        cityLevel,
+			wantVerdicts:  []string{VerdictUnknown, VerdictUnknown, VerdictMatch, VerdictMatch},
+			wantValid:     true,
+			wantSuggested: Address{Cep: "39270-000", Street: "Rua Um", Neighborhood: "Centro", City: "Lassance", State: "MG"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkAddress(tt.addr, tt.result)
+			var verdicts []string
+			for _, f := range got.Fields {
+				verdicts = append(verdicts, f.Verdict)
+			}
+			if !reflect.DeepEqual(verdicts, tt.wantVerdicts) {
+				t.Errorf("checkAddress() verdicts = %v, want %v", verdicts, tt.wantVerdicts)
+			}
+			if got.Valid != tt.wantValid {
+				t.Errorf("checkAddress() valid = %v, want %v", got.Valid, tt.wantValid)
+			}
+			if got.Suggested != tt.wantSuggested {
+				t.Errorf("checkAddress() suggested = %+v, want %+v", got.Suggested, tt.wantSuggested)
+			}
+		})
+	}
+}
+
+func TestCheckField(t *testing.T) {
+	tests := []struct {
+		input, expected string
+		want            string
+	}{
+		{input: "Ibiturna", expected: "Ibituruna", want: VerdictFuzzyMatch},
+		{input: "Rua Herlindo Silvera", expected: "Rua Herlindo Silveira", want: VerdictFuzzyMatch},
+		{input: "Quadra 3 Bloco B", expected: "Qd. 3 Bloco B", want: VerdictMatch},
+		{input: "Quadra 3 Bloko B", expected: "Quadra 3 Bloco B", want: VerdictFuzzyMatch},
+		{input: "Rua A", expected: "Rua B", want: VerdictMismatch},
+		{input: "Quadra 3", expected: "Quadra 4", want: VerdictMismatch},
+		{input: "Quadra 3 Bloco A", expected: "Quadra 3 Bloco B", want: VerdictMismatch},
+		{input: "Rodovia BR-116", expected: "Rodovia BR-117", want: VerdictMismatch},
+		{input: "Rua 12 de Outubro", expected: "Rua 13 de Outubro", want: VerdictMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input+"="+tt.expected, func(t *testing.T) {
+			if got := checkField("street", tt.input, tt.expected); got.Verdict != tt.want {
+				t.Errorf("checkField() verdict = %v (similarity %.2f), want %v", got.Verdict, got.Similarity, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAddressLookup(t *testing.T) {
+	ds, _, err := dataset.LoadCsv(strings.NewReader("cep,uf,cidade,bairro,logradouro\n39408078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterDataset(ds)
+	opts := RaceOptions{Providers: []string{DatasetProviderName}}
+	got, err := CheckAddress(context.Background(), Address{Cep: "39408078", Street: "Av Herlindo Silveira", Neighborhood: "Ibituruna", City: "Montes Claros", State: "MG"}, opts)
+	if err != nil || !got.Valid || got.Result.Provider != DatasetProviderName {
+		t.Errorf("CheckAddress() = %+v, %v, want a valid address from the dataset", got, err)
+	}
+	if _, err := CheckAddress(context.Background(), Address{Cep: "39408079"}, opts); !errors.Is(err, dto.ErrNotFound) {
+		t.Errorf("CheckAddress() error = %v, want dto.ErrNotFound", err)
+	}
+}
@@ -2,34 +2,217 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
 	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/report"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/shared"
 )
 
-// ExecuteQueries starts two goroutines, one for each service, and waits for
-// any of them to finish. If the context is canceled, it logs a message and
-// exits. If a service returns an error, it logs the error. If a service
-// returns a valid response, it reports it.
-func ExecuteQueries(ctx context.Context, cancel context.CancelFunc, cep *string) {
-	q0 := NewQueryBrasilapi(ctx, cancel, *cep)
-	q1 := NewCepQueryViacep(ctx, cancel, *cep)
+// queryResult is the response of a query, tagged with the query that produced it.
+type queryResult struct {
+	query    *CepQuery
+	response dto.Response
+}
 
-	go q0.GetCep()
-	go q1.GetCep()
+var (
+	// ErrInvalidCep is wrapped by the error Lookup returns for a cep rejected before
+	// the race: one without 8 digits or outside the state ranges.
+	ErrInvalidCep = dto.NewCodedError(dto.CodeInvalidCep, "invalid cep", false)
+	// ErrCepNotFound is returned by Lookup when every provider answered that the cep
+	// does not exist. It wraps dto.ErrNotFound.
+	ErrCepNotFound = fmt.Errorf("cep %w by any provider", dto.ErrNotFound)
+	// ErrAllProvidersFailed is returned by Lookup when every provider answered with an
+	// error, not all of them "not found", or none could be queried.
+	ErrAllProvidersFailed = dto.NewCodedError(dto.CodeAllProvidersFailed, "all providers failed", true)
+)
 
-	select {
-	case <-ctx.Done():
+// inflight counts the queries started by Lookup that have not answered yet.
+var inflight sync.WaitGroup
+
+// Result is the winner of a race: the cep and the provider that resolved it.
+type Result struct {
+	Cep dto.Cep `json:"cep"`
+	// Provider is the registry name of the provider, such as "brasilapi-v2".
+	Provider string `json:"provider"`
+	// Service is the service name of the provider, such as "BrasilapiV2".
+	Service string `json:"service"`
+}
+
+// ExecuteQueries resolves the cep with Lookup and reports the winner, canceling
+// the remaining queries, or logs why there is no winner. It returns the error of
+// Lookup.
+func ExecuteQueries(ctx context.Context, cancel context.CancelFunc, cep *string, opts RaceOptions) error {
+	result, err := Lookup(ctx, *cep, opts)
+	switch {
+	case err == nil:
+	case errors.Is(err, context.DeadlineExceeded):
 		slog.Info("ExecuteQueries: Context deadline exceeded")
-	case r0 := <-q0.Channel:
-		if r0.Error != nil {
-			slog.Info("main: " + r0.Error.Error())
+		return err
+	default:
+		slog.Info("ExecuteQueries: " + err.Error())
+		return err
+	}
+	cancel()
+	report.Report(result.Cep, result.Service)
+	return nil
+}
+
+// Lookup starts one goroutine for each provider in opts (the DefaultProviders,
+// Brasilapi v2 and ViaCEP, if none is given) and returns the best valid response,
+// canceling the remaining queries. A cep that does not belong to any state range
+// is rejected before any service is queried.
+//
+// The first valid response wins unless opts has a GraceWindow: then the race
+// keeps waiting up to the grace window for a pending provider ranked higher
+// by opts, and returns the best response received by then.
+//
+// Providers whose circuit breaker is open are left out, and every response is
+// fed to the breakers. A winner that did not come from the cache is stored in
+// the cache registered with RegisterCache, if any.
+//
+// If a service returns an error, it logs the error and keeps waiting for the
+// others. If all of them fail, it returns ErrCepNotFound when every one answered
+// "not found" and ErrAllProvidersFailed otherwise. If the context is canceled or
+// times out before any valid response, it returns the context error. A rejected
+// cep gives an error wrapping ErrInvalidCep.
+func Lookup(ctx context.Context, cep string, opts RaceOptions) (Result, error) {
+	if _, err := shared.ValidateCepRange(cep); err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidCep, err)
+	}
+	providerNames := opts.Providers
+	if len(providerNames) == 0 {
+		providerNames = DefaultProviders
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	queries, err := NewQueries(ctx, cancel, cep, availableProviders(providerNames))
+	if err != nil {
+		return Result{}, err
+	}
+
+	results := make(chan queryResult, len(queries))
+	pending := map[*CepQuery]bool{}
+	for _, q := range queries {
+		pending[q] = true
+		inflight.Add(1)
+		go q.GetCep()
+		go func(q *CepQuery) {
+			response := <-q.Channel
+			response.Provider = q.Provider
+			inflight.Done()
+			recordOutcome(q.Provider, response.Error, ctx.Err() != nil)
+			results <- queryResult{query: q, response: response}
+		}(q)
+	}
+
+	best, errs := race(ctx, results, pending, opts)
+	if best == nil {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		if allNotFound(errs) {
+			return Result{}, ErrCepNotFound
+		}
+		return Result{}, ErrAllProvidersFailed
+	}
+	if resultCache != nil && best.query.Provider != CacheProviderName {
+		resultCache.Put(best.response.Cep)
+	}
+	return Result{Cep: best.response.Cep, Provider: best.query.Provider, Service: best.query.ServiceName}, nil
+}
+
+// Drain waits up to timeout for the queries still running, such as the losers
+// of the races, which log their cancellation as they stop. It reports whether
+// all of them stopped. It must be called once no more lookups are started.
+func Drain(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		select {
+		case <-done:
+			return true
+		default:
+		}
+		return false
+	}
+}
+
+// race receives the results of the pending queries and returns the best valid
+// one, following the ranking and grace window of opts, or nil if every query
+// failed or the context was canceled before any valid result, along with the
+// errors received.
+func race(ctx context.Context, results <-chan queryResult, pending map[*CepQuery]bool, opts RaceOptions) (*queryResult, []error) {
+	var best *queryResult
+	var errs []error
+	var grace <-chan time.Time
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return best, errs
+		case <-grace:
+			return best, errs
+		case r := <-results:
+			delete(pending, r.query)
+			if r.response.Error != nil {
+				logError(r.response.Error)
+				errs = append(errs, r.response.Error)
+				continue
+			}
+			if best == nil || opts.rankOf(r.query.Provider).better(opts.rankOf(best.query.Provider)) {
+				best = &r
+			}
+			if opts.GraceWindow <= 0 || !pendingBetter(pending, best, opts) {
+				return best, errs
+			}
+			if grace == nil {
+				grace = time.After(opts.GraceWindow)
+			}
+		}
+	}
+	return best, errs
+}
+
+// allNotFound reports whether there are errors and all of them are dto.ErrNotFound.
+func allNotFound(errs []error) bool {
+	for _, err := range errs {
+		if !errors.Is(err, dto.ErrNotFound) {
+			return false
 		}
-		report.Report(r0.Cep, q0.ServiceName)
-	case r1 := <-q1.Channel:
-		if r1.Error != nil {
-			slog.Info("main: " + r1.Error.Error())
+	}
+	return len(errs) > 0
+}
+
+// pendingBetter reports whether any pending query ranks higher than the best result.
+func pendingBetter(pending map[*CepQuery]bool, best *queryResult, opts RaceOptions) bool {
+	bestRank := opts.rankOf(best.query.Provider)
+	for q := range pending {
+		if opts.rankOf(q.Provider).better(bestRank) {
+			return true
 		}
-		report.Report(r1.Cep, q1.ServiceName)
 	}
+	return false
+}
+
+// logError logs the error of a service. An upstream error is logged with its
+// details, including the failing sub-services, for diagnostics.
+func logError(err error) {
+	var upstreamErr *dto.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		slog.Info("main: "+err.Error(), "upstream", upstreamErr)
+		return
+	}
+	slog.Info("main: " + err.Error())
 }
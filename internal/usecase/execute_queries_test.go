@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// raceEvent is a response the fake provider sends after a delay.
+type raceEvent struct {
+	provider string
+	delay    time.Duration
+	err      error
+}
+
+// runRace races fake providers that answer as described by events and returns the winner,
+// or "" if there is none.
+func runRace(ctx context.Context, events []raceEvent, opts RaceOptions) string {
+	results := make(chan queryResult, len(events))
+	pending := map[*CepQuery]bool{}
+	for _, e := range events {
+		q := &CepQuery{Provider: e.provider, ServiceName: e.provider}
+		pending[q] = true
+		go func(e raceEvent) {
+			time.Sleep(e.delay)
+			results <- queryResult{query: q, response: dto.NewResponse(dto.Cep{Cep: e.provider}, e.err)}
+		}(e)
+	}
+	best, _ := race(ctx, results, pending, opts)
+	if best == nil {
+		return ""
+	}
+	return best.query.Provider
+}
+
+func TestRace(t *testing.T) {
+	preferDataset := map[string]int{"dataset": 10, "brasilapi-v2": 5}
+	tests := []struct {
+		name    string
+		events  []raceEvent
+		opts    RaceOptions
+		timeout time.Duration
+		want    string
+	}{
+		{
+			name:   "first valid response wins without grace window",
+			events: []raceEvent{{"viacep", 0, nil}, {"dataset", 20 * time.Millisecond, nil}},
+			opts:   RaceOptions{Priorities: preferDataset},
+			want:   "viacep",
+		},
+		{
+			name:   "errors do not win",
+			events: []raceEvent{{"viacep", 0, errors.New("not found")}, {"brasilapi-v2", 20 * time.Millisecond, nil}},
+			opts:   RaceOptions{},
+			want:   "brasilapi-v2",
+		},
+		{
+			name:   "higher priority inside the grace window wins",
+			events: []raceEvent{{"viacep", 0, nil}, {"brasilapi-v2", 20 * time.Millisecond, nil}},
+			opts:   RaceOptions{Priorities: preferDataset, GraceWindow: 200 * time.Millisecond},
+			want:   "brasilapi-v2",
+		},
+		{
+			name:   "higher priority after the grace window loses",
+			events: []raceEvent{{"viacep", 0, nil}, {"brasilapi-v2", 300 * time.Millisecond, nil}},
+			opts:   RaceOptions{Priorities: preferDataset, GraceWindow: 20 * time.Millisecond},
+			want:   "viacep",
+		},
+		{
+			name:   "highest ranked response ends the race without waiting",
+			events: []raceEvent{{"dataset", 0, nil}, {"brasilapi-v2", time.Second, nil}},
+			opts:   RaceOptions{Priorities: preferDataset, GraceWindow: time.Second},
+			want:   "dataset",
+		},
+		{
+			name:   "weight ranks providers of equal priority",
+			events: []raceEvent{{"viacep", 0, nil}, {"postmon", 20 * time.Millisecond, nil}},
+			opts:   RaceOptions{Weights: map[string]float64{"viacep": 0.5}, GraceWindow: 200 * time.Millisecond},
+			want:   "postmon",
+		},
+		{
+			name:    "deadline inside the grace window keeps the best response",
+			events:  []raceEvent{{"viacep", 0, nil}, {"brasilapi-v2", time.Second, nil}},
+			opts:    RaceOptions{Priorities: preferDataset, GraceWindow: time.Second},
+			timeout: 50 * time.Millisecond,
+			want:    "viacep",
+		},
+		{
+			name:   "all providers fail",
+			events: []raceEvent{{"viacep", 0, errors.New("not found")}, {"brasilapi-v2", 0, errors.New("not found")}},
+			opts:   RaceOptions{},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if got := runRace(ctx, tt.events, tt.opts); got != tt.want {
+				t.Errorf("race() winner = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want bool
+	}{
+		{name: "no errors", errs: nil, want: false},
+		{name: "all not found", errs: []error{dto.ErrNotFound, &dto.UpstreamError{StatusCode: 404}}, want: true},
+		{name: "not found and timeout", errs: []error{dto.ErrNotFound, context.DeadlineExceeded}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allNotFound(tt.errs); got != tt.want {
+				t.Errorf("allNotFound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupInvalidCep(t *testing.T) {
+	for _, cep := range []string{"123", "00000000"} {
+		if _, err := Lookup(context.Background(), cep, RaceOptions{}); !errors.Is(err, ErrInvalidCep) {
+			t.Errorf("Lookup(%q) error = %v, want ErrInvalidCep", cep, err)
+		}
+	}
+}
+
+func BenchmarkLookupDataset(b *testing.B) {
+	ds, _, err := dataset.LoadCsv(strings.NewReader("cep,uf,cidade,bairro,logradouro\n39408078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	RegisterDataset(ds)
+	opts := RaceOptions{Providers: []string{DatasetProviderName}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := Lookup(context.Background(), "39408078", opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLookupHTTP(b *testing.B) {
+	viacep, err := os.ReadFile("../../responses/viacep.200.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	brasilapi, err := os.ReadFile("../../responses/brasilapi.v2.200.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/ws/") {
+			w.Write(viacep)
+			return
+		}
+		w.Write(brasilapi)
+	}))
+	defer server.Close()
+	for name, path := range map[string]string{"viacep": "/ws/{{cep}}/json/", "brasilapi-v2": "/api/cep/v2/{{cep}}"} {
+		client, _ := NewClient(ClientOptions{})
+		ConfigureProvider(name, ProviderSettings{URL: server.URL + path, Client: client})
+		defer ConfigureProvider(name, ProviderSettings{})
+	}
+	defer func(d time.Duration) { SimulatedDelay = d }(SimulatedDelay)
+	SimulatedDelay = 0
+	opts := RaceOptions{Providers: []string{"brasilapi-v2", "viacep"}}
+	lookup := func(b *testing.B) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := Lookup(ctx, "39408078", opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			lookup(b)
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				lookup(b)
+			}
+		})
+	})
+	Drain(time.Second)
+}
@@ -3,33 +3,94 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"math/rand"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
 )
 
+// DefaultMaxBodySize is the largest response body read from a provider when the
+// query does not set MaxBodySize. The bodies of the providers have a few hundred bytes.
+const DefaultMaxBodySize = 64 << 10
+
+// drainSize is how much of an unread body is discarded before closing it, so the
+// connection can be reused; a longer body closes the connection instead.
+const drainSize = 4 << 10
+
+// SimulatedDelay is the longest of the random delays GetCep waits before an HTTP
+// query, to simulate a real-world scenario. Zero disables the delay, as the
+// benchmarks and the load test do. It is meant to be set at startup.
+var SimulatedDelay = 1500 * time.Millisecond
+
+var (
+	// ErrBodyTooLarge is wrapped by the error of a query whose response body is
+	// larger than its MaxBodySize.
+	ErrBodyTooLarge = dto.NewCodedError(dto.CodeBodyTooLarge, "response body too large", false)
+	// ErrUnexpectedContentType is wrapped by the error of a query whose 200 response
+	// is not JSON, such as the HTML error page of ViaCEP.
+	ErrUnexpectedContentType = dto.NewCodedError(dto.CodeUnexpectedContentType, "unexpected content type", false)
+)
+
 type CepQuery struct {
-	Context            context.Context
-	Cancel             context.CancelFunc
-	Cep                string
-	ServiceName        string
+	Context     context.Context
+	Cancel      context.CancelFunc
+	Cep         string
+	ServiceName string
+	// Provider is the name the query's provider is registered under, such as "brasilapi-v2".
+	Provider           string
 	Channel            chan dto.Response
 	url                string
 	ExtractCepFromBody func(c *CepQuery, body []byte) (dto.Cep, bool)
+	// ExtractErrorFromBody parses the body of a non-200 response into an error
+	// carrying the upstream message. It returns nil when the body is not in the
+	// provider's error format, in which case a generic error is used.
+	ExtractErrorFromBody func(c *CepQuery, statusCode int, body []byte) error
+	// Lookup resolves the cep without HTTP, for local providers such as the
+	// DNE dataset. When it is set, GetCep calls it instead of querying a url.
+	Lookup func(c *CepQuery) (dto.Cep, error)
+	// Timeout bounds the query on top of Context; zero leaves it to Context.
+	Timeout time.Duration
+	// Retry tells how many times an HTTP query is tried; see RetryPolicy.
+	Retry RetryPolicy
+	// Client sends the HTTP query; nil uses a client shared by the providers
+	// without a client of their own. See NewClient.
+	Client *http.Client
+	// MaxBodySize bounds the response body; zero means DefaultMaxBodySize.
+	MaxBodySize int64
 }
 
 // GetCep executes a GET request on the given cep, using the given context.
-// It first waits a random time between 1 and 1500 milliseconds, to simulate
-// a real-world scenario.
-// If the context is canceled, it prints a message and returns.
+// A local provider, which sets Lookup, is resolved by lookupLocal instead.
+// It first waits a random time up to SimulatedDelay, 1500 milliseconds by default,
+// to simulate a real-world scenario, unless the context is canceled meanwhile.
+// If the context is canceled, it prints a message and sends the context error to the channel.
 // Otherwise, it executes the request and sends the response to the given channel.
+// Either way exactly one response is sent, so the channel can be drained by a single receive.
+// If the query has a Timeout, the context is bounded by it for the whole query.
 func (c *CepQuery) GetCep() {
-	time.Sleep(time.Duration(rand.Intn(1500)+1) * time.Millisecond)
+	if c.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(c.Context, c.Timeout)
+		defer cancel()
+		c.Context = ctx
+	}
+	if c.Lookup != nil {
+		lookupLocal(c)
+		return
+	}
+
+	if SimulatedDelay > 0 {
+		select {
+		case <-c.Context.Done():
+		case <-time.After(time.Duration(rand.Int63n(int64(SimulatedDelay)) + 1)):
+		}
+	}
 
 	req, shouldReturn := prepareUrl(c)
 	if shouldReturn {
@@ -39,6 +100,7 @@ func (c *CepQuery) GetCep() {
 	select {
 	case <-c.Context.Done():
 		slog.Info(c.ServiceName + ": canceled context")
+		c.Channel <- dto.NewResponse(dto.Cep{}, c.Context.Err())
 		return
 	default:
 		executeQuery(req, c)
@@ -46,52 +108,84 @@ func (c *CepQuery) GetCep() {
 
 }
 
-// executeQuery performs an HTTP request using the provided request object and processes the response.
+// executeQuery performs an HTTP request using the provided request object, retried as told
+// by the query's RetryPolicy, and processes the response.
 // It sends the result to the CepQuery's channel. If an error occurs during the request, it sends the error to the channel.
-// The function handles different HTTP status codes by sending appropriate error messages to the channel.
-// In case of a 200 OK status, it processes the response body asynchronously.
+// For a status other than 200 it sends the error built by processHttpResponseError to the channel.
+// In case of a 200 OK status, it processes the response body with processHttpResponseOk.
 func executeQuery(req *http.Request, c *CepQuery) {
-	res, err := http.DefaultClient.Do(req)
+	res, err := doWithRetry(req, c)
 	if err != nil {
 		c.Channel <- dto.NewResponse(dto.Cep{}, err)
 		return
 	}
 
-	switch res.StatusCode {
-	case http.StatusOK:
-		go processHttpResponseOk(res, c)
+	if res.StatusCode == http.StatusOK {
+		processHttpResponseOk(res, c)
+		return
+	}
+	c.Channel <- dto.NewResponse(dto.Cep{}, processHttpResponseError(res, c))
+}
+
+// processHttpResponseError reads the body of a non-200 response and asks the
+// ExtractErrorFromBody method to turn it into an error with the upstream message
+// and failing sub-services.
+// If the body cannot be read, is larger than MaxBodySize or is not in the provider's
+// error format, it returns a dto.UpstreamError with a generic message for the status code.
+// The body is closed.
+func processHttpResponseError(res *http.Response, c *CepQuery) error {
+	defer closeBody(res)
+	body, err := readBody(res, c)
+	if err == nil && c.ExtractErrorFromBody != nil {
+		if upstreamErr := c.ExtractErrorFromBody(c, res.StatusCode, body); upstreamErr != nil {
+			return upstreamErr
+		}
+	}
+	return &dto.UpstreamError{
+		Service:    c.ServiceName,
+		StatusCode: res.StatusCode,
+		Message:    statusMessage(res.StatusCode),
+	}
+}
+
+// statusMessage returns the generic message for a non-200 status code.
+func statusMessage(statusCode int) string {
+	switch statusCode {
 	case http.StatusRequestTimeout:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("time exceeded"))
+		return "time exceeded"
 	case http.StatusNotFound:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("not found"))
+		return "not found"
 	case http.StatusBadRequest:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("cep must have 8 digits"))
+		return "cep must have 8 digits"
 	case http.StatusInternalServerError:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("internal server error"))
+		return "internal server error"
 	case http.StatusServiceUnavailable:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("service unavailable"))
+		return "service unavailable"
 	default:
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("unknown error"))
+		return "unknown error"
 	}
 }
 
-// processHttpResponseOk reads the response body from the given http.Response object,
-// checks if the response is a JSON object with an "erro" key set to "true",
-// and if it is, sends an error to the channel.
-// If the response does not contain the error key, it calls the ExtractCepFromBody method
-// to process the response body.
+// processHttpResponseOk reads the response body from the given http.Response object
+// and calls the ExtractCepFromBody method to process it. Provider-specific envelopes,
+// such as ViaCEP's {"erro": true} for a cep that does not exist, are decoded there.
+// A body that is not JSON, judging by its Content-Type, or is larger than MaxBodySize
+// is not read, and an error wrapping ErrUnexpectedContentType or ErrBodyTooLarge is
+// sent to the channel. The body is closed.
 // If the ExtractCepFromBody method returns an error, it sends the error to the channel.
-// If the ExtractCepFromBody method returns a Cep object, it sends the object to the channel.
-// After sending to the channel, it cancels the context.
+// If the ExtractCepFromBody method returns a Cep object, it flags it when its state does not
+// match the cep range, splits its street with dto.Cep.ParseStreet and sends the object to
+// the channel.
+// Canceling the slower queries is left to ExecuteQueries, which picks the winner.
 func processHttpResponseOk(res *http.Response, c *CepQuery) {
-	body, error := io.ReadAll(res.Body)
-	if error != nil {
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("fail to read the body response: "+error.Error()))
+	defer closeBody(res)
+	if err := checkContentType(res, c); err != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
 		return
 	}
-
-	if strings.Contains(string(body), `"erro": "true"`) {
-		c.Channel <- dto.NewResponse(dto.Cep{}, errors.New("not found"))
+	body, error := readBody(res, c)
+	if error != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, error)
 		return
 	}
 
@@ -99,22 +193,115 @@ func processHttpResponseOk(res *http.Response, c *CepQuery) {
 	if shouldReturn {
 		return
 	}
+	flagInconsistency(c, &cep)
+	cep.ParseStreet()
+
+	c.Channel <- dto.NewResponse(cep, nil)
+}
+
+// readBody reads the body of res up to the query's MaxBodySize. It returns an error
+// wrapping ErrBodyTooLarge, without reading it, for a body announced larger than the
+// limit, or after reading the limit for a body of unknown length.
+func readBody(res *http.Response, c *CepQuery) ([]byte, error) {
+	limit := c.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	tooLarge := fmt.Errorf("%s: %w, limit is %d bytes", c.ServiceName, ErrBodyTooLarge, limit)
+	if res.ContentLength > limit {
+		return nil, tooLarge
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+	if err != nil {
+		return nil, errors.New("fail to read the body response: " + err.Error())
+	}
+	if int64(len(body)) > limit {
+		return nil, tooLarge
+	}
+	return body, nil
+}
+
+// checkContentType returns an error wrapping ErrUnexpectedContentType unless the
+// Content-Type of res is JSON. A missing Content-Type and text/plain, used by servers
+// that do not label their JSON, are accepted; the body is checked when decoded.
+func checkContentType(res *http.Response, c *CepQuery) error {
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || mediaType == "text/json" ||
+		mediaType == "text/plain" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w %s", c.ServiceName, ErrUnexpectedContentType, strconv.Quote(contentType))
+}
+
+// closeBody discards what is left of the body of res, up to drainSize, so the
+// connection goes back to the pool, and closes it.
+func closeBody(res *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(res.Body, drainSize))
+	res.Body.Close()
+}
 
+// lookupLocal resolves the cep with the Lookup method of a local provider and sends
+// the result to the channel, flagging it when its state does not match the cep range and
+// splitting its street.
+// If the context is already canceled, it sends the context error instead.
+func lookupLocal(c *CepQuery) {
+	if err := c.Context.Err(); err != nil {
+		slog.Info(c.ServiceName + ": canceled context")
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
+		return
+	}
+	cep, err := c.Lookup(c)
+	if err != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
+		return
+	}
+	flagInconsistency(c, &cep)
+	cep.ParseStreet()
 	c.Channel <- dto.NewResponse(cep, nil)
-	c.Cancel()
 }
 
 // prepareUrl creates a new HTTP GET request with the given context, using the URL
 // stored in the CepQuery object, replacing the "{{cep}}" placeholder with the
-// actual cep.
+// actual cep. The context of the request tells the provider and the cep to
+// RequestQuery.
 // If the request creation fails, it sends an error to the channel and returns true.
 // Otherwise, it returns the created request and false.
 func prepareUrl(c *CepQuery) (*http.Request, bool) {
 	url := strings.Replace(c.url, "{{cep}}", c.Cep, 1)
-	req, err := http.NewRequestWithContext(c.Context, "GET", url, nil)
+	ctx := context.WithValue(c.Context, queryKey{}, c)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		c.Channel <- dto.NewResponse(dto.Cep{}, err)
 		return nil, true
 	}
 	return req, false
 }
+
+// queryKey is the context key of the query a request was made for.
+type queryKey struct{}
+
+// RequestQuery returns the provider and the cep of the query req was made for,
+// so a transport, such as a cassette, can tell the requests apart. It returns
+// false for a request not made by a query of a registered provider, as created
+// by NewQueries.
+func RequestQuery(req *http.Request) (provider, cep string, ok bool) {
+	c, ok := req.Context().Value(queryKey{}).(*CepQuery)
+	if !ok || c.Provider == "" {
+		return "", "", false
+	}
+	return c.Provider, c.Cep, true
+}
+
+// flagInconsistency marks the cep as inconsistent and logs a warning when the state
+// returned by the service is not the state that owns the cep range.
+func flagInconsistency(c *CepQuery, cep *dto.Cep) {
+	if cep.MatchesCepRange() {
+		return
+	}
+	cep.Inconsistent = true
+	slog.Warn(c.ServiceName+": state does not match the cep range", "cep", cep)
+}
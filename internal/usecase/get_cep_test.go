@@ -2,7 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,6 +41,8 @@ func TestGetCepViacep(t *testing.T) {
 				City:         "Montes Claros",
 				Neighborhood: "Ibituruna",
 				Street:       "Avenida Herlindo Silveira",
+				StreetType:   "Avenida",
+				StreetName:   "Herlindo Silveira",
 			},
 				Error: nil,
 			},
@@ -78,6 +86,8 @@ func TestGetCepBrasilapi(t *testing.T) {
 				City:         "Montes Claros",
 				Neighborhood: "Ibituruna",
 				Street:       "Avenida Herlindo Silveira",
+				StreetType:   "Avenida",
+				StreetName:   "Herlindo Silveira",
 			},
 				Error: nil,
 			},
@@ -94,3 +104,131 @@ func TestGetCepBrasilapi(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessHttpResponseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        *CepQuery
+		statusCode   int
+		fixture      string
+		body         string
+		wantMessage  string
+		wantServices []string
+	}{
+		{
+			name:         "brasilapi 404",
+			query:        NewQueryBrasilapi(context.Background(), func() {}, "39408079"),
+			statusCode:   http.StatusNotFound,
+			fixture:      "../../responses/brasilapi.404.json",
+			wantMessage:  "Todos os serviços de CEP retornaram erro.",
+			wantServices: []string{"correios", "viacep", "widenet", "correios-alt"},
+		},
+		{
+			name:         "brasilapi 400",
+			query:        NewQueryBrasilapi(context.Background(), func() {}, "394080788"),
+			statusCode:   http.StatusBadRequest,
+			fixture:      "../../responses/brasilapi.400.json",
+			wantMessage:  "CEP deve conter exatamente 8 caracteres.",
+			wantServices: []string{"cep_validation"},
+		},
+		{
+			name:         "viacep 400",
+			query:        NewCepQueryViacep(context.Background(), func() {}, "3940807"),
+			statusCode:   http.StatusBadRequest,
+			fixture:      "../../responses/viacep.400.html",
+			wantMessage:  "Verifique a URL: Bad Request",
+			wantServices: []string{},
+		},
+		{
+			name:         "unknown body",
+			query:        NewCepQueryViacep(context.Background(), func() {}, "39408078"),
+			statusCode:   http.StatusServiceUnavailable,
+			body:         "upstream connect error",
+			wantMessage:  "service unavailable",
+			wantServices: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if tt.fixture != "" {
+				b, err := os.ReadFile(tt.fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = string(b)
+			}
+			res := &http.Response{StatusCode: tt.statusCode, Body: io.NopCloser(strings.NewReader(body))}
+			err := processHttpResponseError(res, tt.query)
+			var upstreamErr *dto.UpstreamError
+			if !errors.As(err, &upstreamErr) {
+				t.Fatalf("processHttpResponseError() = %v, want a *dto.UpstreamError", err)
+			}
+			if upstreamErr.Service != tt.query.ServiceName || upstreamErr.StatusCode != tt.statusCode || upstreamErr.Message != tt.wantMessage {
+				t.Errorf("processHttpResponseError() = %+v, want message %v", upstreamErr, tt.wantMessage)
+			}
+			if services := upstreamErr.FailingServices(); !reflect.DeepEqual(services, tt.wantServices) {
+				t.Errorf("UpstreamError.FailingServices() = %v, want %v", services, tt.wantServices)
+			}
+		})
+	}
+}
+
+// trackedBody is a response body that records whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestProcessHttpResponseOk(t *testing.T) {
+	fixture, err := os.ReadFile("../../responses/viacep.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile("../../responses/viacep.400.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		contentLength int64
+		maxBodySize   int64
+		wantErr       error
+	}{
+		{name: "json", contentType: "application/json; charset=utf-8", body: string(fixture)},
+		{name: "unlabeled json", body: string(fixture)},
+		{name: "json labeled as text", contentType: "text/plain; charset=utf-8", body: string(fixture)},
+		{name: "html error page", contentType: "text/html; charset=utf-8", body: string(html), wantErr: ErrUnexpectedContentType},
+		{name: "announced too large", contentType: "application/json", body: string(fixture), contentLength: 1 << 30, wantErr: ErrBodyTooLarge},
+		{name: "streamed too large", contentType: "application/json", body: string(fixture), contentLength: -1, maxBodySize: 64, wantErr: ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewCepQueryViacep(context.Background(), func() {}, "39408078")
+			q.MaxBodySize = tt.maxBodySize
+			body := &trackedBody{Reader: strings.NewReader(tt.body)}
+			res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body, ContentLength: tt.contentLength}
+			if tt.contentType != "" {
+				res.Header.Set("Content-Type", tt.contentType)
+			}
+			processHttpResponseOk(res, q)
+			got := <-q.Channel
+			if !errors.Is(got.Error, tt.wantErr) {
+				t.Errorf("processHttpResponseOk() error = %v, want %v", got.Error, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Cep.City != "Montes Claros" {
+				t.Errorf("processHttpResponseOk() = %+v, want Montes Claros", got.Cep)
+			}
+			if !body.closed {
+				t.Error("processHttpResponseOk() did not close the body")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent of the queries when ClientOptions do not set one.
+const DefaultUserAgent = "fullcycle-multithreading-cep/1.0 (+https://github.com/antoniofmoliveira/fullcycle-multithreading)"
+
+// ClientOptions tune the HTTP client of a provider. Zero values keep the defaults:
+// DefaultUserAgent, the proxy of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables, 16 idle connections kept per host for 90 seconds, and 5 seconds for the
+// TLS handshake and 10 for the response headers.
+type ClientOptions struct {
+	UserAgent string
+	// Proxy is the url of the proxy, such as "http://proxy.local:3128".
+	Proxy                 string
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+}
+
+// defaultClient is the client of the queries whose provider has no client of its own.
+var defaultClient = newClient(ClientOptions{}, http.ProxyFromEnvironment)
+
+// NewClient returns an HTTP client with a transport of its own, tuned by opts, that keeps
+// the connections alive between queries, negotiates HTTP/2 and sends the User-Agent.
+// It has no overall timeout: the queries are bounded by their contexts.
+// It returns an error if the proxy is not an absolute url.
+func NewClient(opts ClientOptions) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errors.New("invalid proxy url " + opts.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+	return newClient(opts, proxy), nil
+}
+
+// newClient is NewClient with the proxy already resolved.
+func newClient(opts ClientOptions, proxy func(*http.Request) (*url.URL, error)) *http.Client {
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   orDefault(opts.MaxIdleConnsPerHost, 16),
+		IdleConnTimeout:       orDefault(opts.IdleConnTimeout, 90*time.Second),
+		TLSHandshakeTimeout:   orDefault(opts.TLSHandshakeTimeout, 5*time.Second),
+		ResponseHeaderTimeout: orDefault(opts.ResponseHeaderTimeout, 10*time.Second),
+		ExpectContinueTimeout: time.Second,
+	}
+	return &http.Client{Transport: &userAgentTransport{userAgent: opts.UserAgent, next: transport}}
+}
+
+// orDefault returns v, or def if v is zero.
+func orDefault[T comparable](v, def T) T {
+	var zero T
+	if v == zero {
+		return def
+	}
+	return v
+}
+
+// userAgentTransport sets the User-Agent of the requests that do not have one.
+type userAgentTransport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+// RoundTrip sends a copy of req with the User-Agent set.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport.
+func (t *userAgentTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      ClientOptions
+		header    string
+		wantAgent string
+		wantErr   bool
+	}{
+		{name: "default user agent", wantAgent: DefaultUserAgent},
+		{name: "configured user agent", opts: ClientOptions{UserAgent: "support-desk/2"}, wantAgent: "support-desk/2"},
+		{name: "request user agent kept", opts: ClientOptions{UserAgent: "support-desk/2"}, header: "curl/8", wantAgent: "curl/8"},
+		{name: "invalid proxy", opts: ClientOptions{Proxy: "proxy.local:3128"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.UserAgent()
+			}))
+			defer server.Close()
+
+			client, err := NewClient(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			if tt.header != "" {
+				req.Header.Set("User-Agent", tt.header)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if got != tt.wantAgent {
+				t.Errorf("User-Agent = %q, want %q", got, tt.wantAgent)
+			}
+		})
+	}
+}
+
+func TestExecuteQueryClient(t *testing.T) {
+	body, err := os.ReadFile("../../responses/viacep.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.UserAgent())
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(ClientOptions{UserAgent: "injected/1"})
+	ConfigureProvider("viacep", ProviderSettings{URL: server.URL + "/ws/{{cep}}/json/", Client: client})
+	defer ConfigureProvider("viacep", ProviderSettings{})
+
+	for range 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		queries, err := NewQueries(ctx, cancel, "39408078", []string{"viacep"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := prepareUrl(queries[0])
+		executeQuery(req, queries[0])
+		if got := <-queries[0].Channel; got.Error != nil {
+			t.Errorf("executeQuery() error = %v", got.Error)
+		}
+		cancel()
+	}
+	if len(agents) != 2 || agents[0] != "injected/1" {
+		t.Errorf("User-Agents = %q, want the injected client's twice", agents)
+	}
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// RetryPolicy tells how many times a query tries an HTTP provider that fails
+// with a network error or a 5xx status.
+type RetryPolicy struct {
+	// Attempts is the total number of tries; 0 and 1 both mean a single try.
+	Attempts int
+	// Backoff is the pause between two tries.
+	Backoff time.Duration
+}
+
+// BreakerPolicy configures the circuit breaker of a provider: after Failures
+// consecutive failures the provider is left out of the races for Cooldown.
+type BreakerPolicy struct {
+	// Failures is the number of consecutive failures that opens the circuit; 0 disables it.
+	Failures int
+	Cooldown time.Duration
+}
+
+// ProviderSettings override how the queries of a provider are made.
+// Zero values keep the provider defaults.
+type ProviderSettings struct {
+	// URL replaces the url template of an HTTP provider; it must contain "{{cep}}".
+	URL string
+	// Timeout bounds each query of the provider, on top of the race context.
+	Timeout time.Duration
+	Retry   RetryPolicy
+	Breaker BreakerPolicy
+	// Client sends the queries of an HTTP provider, so it keeps its own connections;
+	// see NewClient.
+	Client *http.Client
+}
+
+var (
+	settings = map[string]ProviderSettings{}
+	breakers = map[string]*breaker{}
+)
+
+// ConfigureProvider sets the settings of the named provider, replacing any settings
+// given before and resetting its circuit breaker. Like RegisterProvider, it is meant
+// to be called at startup, before any query is created.
+func ConfigureProvider(name string, s ProviderSettings) {
+	name = strings.ToLower(name)
+	settings[name] = s
+	delete(breakers, name)
+	if s.Breaker.Failures > 0 {
+		breakers[name] = &breaker{policy: s.Breaker}
+	}
+}
+
+// applySettings copies the settings of the query's provider into the query.
+func applySettings(q *CepQuery) {
+	s, ok := settings[q.Provider]
+	if !ok {
+		return
+	}
+	if s.URL != "" && q.Lookup == nil {
+		q.url = s.URL
+	}
+	q.Timeout = s.Timeout
+	q.Retry = s.Retry
+	q.Client = s.Client
+}
+
+// doWithRetry sends the request with the query's client, trying again, after the
+// backoff of the query's RetryPolicy, while it fails with a network error or a 5xx
+// status and attempts are left. The body of a discarded response is drained and closed.
+func doWithRetry(req *http.Request, c *CepQuery) (*http.Response, error) {
+	client := c.Client
+	if client == nil {
+		client = defaultClient
+	}
+	for attempt := 1; ; attempt++ {
+		res, err := client.Do(req)
+		if attempt >= c.Retry.Attempts || !retryable(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+		if res != nil {
+			closeBody(res)
+		}
+		slog.Info(c.ServiceName+": retrying", "attempt", attempt+1)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(c.Retry.Backoff):
+		}
+	}
+}
+
+// retryable reports whether a request that ended with res and err is worth another try.
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return res.StatusCode >= http.StatusInternalServerError
+}
+
+// breaker counts the consecutive failures of a provider.
+type breaker struct {
+	mu        sync.Mutex
+	policy    BreakerPolicy
+	failures  int
+	openUntil time.Time
+}
+
+// allow reports whether the provider may be queried, that is, whether its circuit is closed
+// or its cooldown is over.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+// record counts a failure, opening the circuit when the policy's limit is reached,
+// or resets the count on success.
+func (b *breaker) record(failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.policy.Failures {
+		b.failures = 0
+		b.openUntil = now.Add(b.policy.Cooldown)
+	}
+}
+
+// availableProviders returns the names whose circuit breaker lets them be queried,
+// logging the ones left out.
+func availableProviders(names []string) []string {
+	now := time.Now()
+	available := make([]string, 0, len(names))
+	for _, name := range names {
+		if b, ok := breakers[name]; ok && !b.allow(now) {
+			slog.Info("ExecuteQueries: circuit open, skipping " + name)
+			continue
+		}
+		available = append(available, name)
+	}
+	return available
+}
+
+// recordOutcome feeds the response of a provider to its circuit breaker.
+// Only provider failures count: network errors, timeouts of the provider itself
+// and 5xx statuses. A cep not found, a rejected cep, or the cancellation of the
+// race are not the provider's fault.
+func recordOutcome(provider string, err error, raceCanceled bool) {
+	b, ok := breakers[provider]
+	if !ok || raceCanceled {
+		return
+	}
+	b.record(isProviderFailure(err), time.Now())
+}
+
+// isProviderFailure reports whether err means the provider is failing.
+func isProviderFailure(err error) bool {
+	if err == nil || errors.Is(err, dto.ErrNotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var upstreamErr *dto.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func TestExecuteQueryRetry(t *testing.T) {
+	body, err := os.ReadFile("../../responses/viacep.200.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		failures  int32
+		attempts  int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "retry succeeds after failures", failures: 2, attempts: 3, wantCalls: 3},
+		{name: "retry gives up", failures: 5, attempts: 2, wantErr: true, wantCalls: 2},
+		{name: "retry disabled", failures: 1, attempts: 0, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			q := NewCepQueryViacep(ctx, cancel, "39408078")
+			q.url = server.URL + "/ws/{{cep}}/json/"
+			q.Retry = RetryPolicy{Attempts: tt.attempts, Backoff: time.Millisecond}
+			req, _ := prepareUrl(q)
+			executeQuery(req, q)
+			got := <-q.Channel
+			if (got.Error != nil) != tt.wantErr {
+				t.Errorf("executeQuery() error = %v, wantErr %v", got.Error, tt.wantErr)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("executeQuery() made %d calls, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	b := &breaker{policy: BreakerPolicy{Failures: 2, Cooldown: time.Minute}}
+	now := time.Now()
+	b.record(true, now)
+	b.record(false, now)
+	b.record(true, now)
+	if !b.allow(now) {
+		t.Errorf("allow() = false after a success reset the failures, want true")
+	}
+	b.record(true, now)
+	if b.allow(now) {
+		t.Errorf("allow() = true after 2 consecutive failures, want false")
+	}
+	if !b.allow(now.Add(time.Minute)) {
+		t.Errorf("allow() = false after the cooldown, want true")
+	}
+}
+
+func TestIsProviderFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "success", err: nil, want: false},
+		{name: "not found", err: dto.ErrNotFound, want: false},
+		{name: "race canceled", err: context.Canceled, want: false},
+		{name: "provider timeout", err: context.DeadlineExceeded, want: true},
+		{name: "upstream 404", err: &dto.UpstreamError{StatusCode: 404}, want: false},
+		{name: "upstream 503", err: &dto.UpstreamError{StatusCode: 503}, want: true},
+		{name: "network error", err: errors.New("connection refused"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isProviderFailure(tt.err); got != tt.want {
+				t.Errorf("isProviderFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueriesAppliesSettings(t *testing.T) {
+	ConfigureProvider("postmon", ProviderSettings{URL: "http://localhost:8080/{{cep}}", Timeout: time.Second, Retry: RetryPolicy{Attempts: 2}})
+	defer delete(settings, "postmon")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	queries, err := NewQueries(ctx, cancel, "39408078", []string{"postmon"})
+	if err != nil {
+		t.Fatalf("NewQueries() error = %v", err)
+	}
+	q := queries[0]
+	if q.url != "http://localhost:8080/{{cep}}" || q.Timeout != time.Second || q.Retry.Attempts != 2 {
+		t.Errorf("NewQueries() = %+v, want the configured settings", q)
+	}
+}
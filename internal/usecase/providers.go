@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// NewQueryFunc creates a CepQuery for one provider.
+type NewQueryFunc func(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery
+
+var providers = map[string]NewQueryFunc{
+	"brasilapi":    NewQueryBrasilapi,
+	"brasilapi-v2": NewQueryBrasilapiV2,
+	"viacep":       NewCepQueryViacep,
+	"awesomeapi":   NewQueryAwesomeapi,
+	"opencep":      NewQueryOpencep,
+	"postmon":      NewQueryPostmon,
+}
+
+// DefaultProviders are the providers raced when none is selected.
+var DefaultProviders = []string{"brasilapi-v2", "viacep"}
+
+// Providers returns the names of all built-in providers, sorted.
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseProviders splits a comma-separated list of provider names, such as
+// "brasilapi-v2,viacep,postmon", ignoring case, spaces and repeated names.
+// An empty list selects DefaultProviders.
+// It returns an error naming the available providers if any name is unknown.
+func ParseProviders(list string) ([]string, error) {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := providers[name]; !ok {
+			return nil, errors.New("unknown provider " + name + ", available providers: " + strings.Join(Providers(), ", "))
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return append([]string(nil), DefaultProviders...), nil
+	}
+	return names, nil
+}
+
+// RegisterProvider makes a provider selectable by name, replacing any provider
+// already registered under that name. It is meant to be called at startup, before
+// any query is created, for providers that need setup such as a loaded dataset.
+func RegisterProvider(name string, newQuery NewQueryFunc) {
+	providers[strings.ToLower(name)] = newQuery
+}
+
+// NewQueries creates one CepQuery per provider name, in the given order,
+// recording the name in the Provider field and applying the settings given
+// to ConfigureProvider.
+// It returns an error if any name is unknown.
+func NewQueries(ctx context.Context, cancel context.CancelFunc, cep string, names []string) ([]*CepQuery, error) {
+	queries := make([]*CepQuery, 0, len(names))
+	for _, name := range names {
+		newQuery, ok := providers[name]
+		if !ok {
+			return nil, errors.New("unknown provider " + name)
+		}
+		q := newQuery(ctx, cancel, cep)
+		q.Provider = name
+		applySettings(q)
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+func TestParseProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "parse providers", list: "viacep,postmon", want: []string{"viacep", "postmon"}},
+		{name: "parse providers with spaces, case and repetition", list: " ViaCEP , opencep,viacep", want: []string{"viacep", "opencep"}},
+		{name: "parse providers empty", list: "", want: DefaultProviders},
+		{name: "parse providers unknown", list: "viacep,correios", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProviders(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseProviders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProviders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueries(t *testing.T) {
+	queries, err := NewQueries(context.Background(), func() {}, "39408078", Providers())
+	if err != nil {
+		t.Fatalf("NewQueries() error = %v", err)
+	}
+	if len(queries) != len(Providers()) {
+		t.Fatalf("NewQueries() returned %d queries, want %d", len(queries), len(Providers()))
+	}
+	for _, q := range queries {
+		if q.ServiceName == "" || q.Channel == nil || (q.Lookup == nil && (q.url == "" || q.ExtractCepFromBody == nil)) {
+			t.Errorf("NewQueries() returned an incomplete query %+v", q)
+		}
+	}
+	if _, err := NewQueries(context.Background(), func() {}, "39408078", []string{"correios"}); err == nil {
+		t.Errorf("NewQueries() error = nil, want error for unknown provider")
+	}
+}
+
+func TestExtractCepFromBodyFixtures(t *testing.T) {
+	lat, lon := -16.7350934, -43.8772265
+	address := dto.Cep{
+		Cep:          "39408078",
+		State:        "MG",
+		City:         "Montes Claros",
+		Neighborhood: "Ibituruna",
+		Street:       "Avenida Herlindo Silveira",
+	}
+	withDash := address
+	withDash.Cep = "39408-078"
+	withCoordinates := address
+	withCoordinates.Latitude, withCoordinates.Longitude = &lat, &lon
+
+	tests := []struct {
+		name     string
+		provider string
+		fixture  string
+		want     dto.Cep
+	}{
+		{name: "brasilapi", provider: "brasilapi", fixture: "brasilapi.200.json", want: address},
+		{name: "brasilapi v2", provider: "brasilapi-v2", fixture: "brasilapi.v2.200.json", want: withCoordinates},
+		{name: "viacep", provider: "viacep", fixture: "viacep.200.json", want: withDash},
+		{name: "awesomeapi", provider: "awesomeapi", fixture: "awesomeapi.200.json", want: withCoordinates},
+		{name: "opencep", provider: "opencep", fixture: "opencep.200.json", want: withDash},
+		{name: "postmon", provider: "postmon", fixture: "postmon.200.json", want: address},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := os.ReadFile("../../responses/" + tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			queries, err := NewQueries(context.Background(), func() {}, "39408078", []string{tt.provider})
+			if err != nil {
+				t.Fatal(err)
+			}
+			q := queries[0]
+			got, shouldReturn := q.ExtractCepFromBody(q, body)
+			if shouldReturn {
+				t.Fatalf("ExtractCepFromBody() failed: %v", <-q.Channel)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractCepFromBody() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueryDataset(t *testing.T) {
+	ds, _, err := dataset.LoadCsv(strings.NewReader("cep,uf,cidade,bairro,logradouro\n39408078,MG,Montes Claros,Ibituruna,Avenida Herlindo Silveira\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := dto.Cep{
+		Cep:          "39408078",
+		State:        "MG",
+		City:         "Montes Claros",
+		Neighborhood: "Ibituruna",
+		Street:       "Avenida Herlindo Silveira",
+		StreetType:   "Avenida",
+		StreetName:   "Herlindo Silveira",
+	}
+
+	q := NewQueryDataset(context.Background(), func() {}, "39408-078", ds)
+	q.GetCep()
+	if r := <-q.Channel; r.Error != nil || !reflect.DeepEqual(r.Cep, want) {
+		t.Errorf("GetCep() = %v, want %v", r, want)
+	}
+
+	q = NewQueryDataset(context.Background(), func() {}, "39408079", ds)
+	q.GetCep()
+	if r := <-q.Channel; !errors.Is(r.Error, dto.ErrNotFound) {
+		t.Errorf("GetCep() error = %v, want %v", r.Error, dto.ErrNotFound)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q = NewQueryDataset(ctx, cancel, "39408078", ds)
+	q.GetCep()
+	if r := <-q.Channel; !errors.Is(r.Error, context.Canceled) {
+		t.Errorf("GetCep() error = %v, want %v", r.Error, context.Canceled)
+	}
+}
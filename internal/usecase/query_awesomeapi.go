@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// AwesomeapiExtractCepFromBody takes a *CepQuery and a JSON body, attempts to parse it as a dto.Awesomeapi,
+// and if successful, converts it to a dto.Cep, including the coordinates when AwesomeAPI knows them.
+// If the parsing fails, it sends an error to the query's channel and returns an empty dto.Cep and true.
+// Otherwise, it returns the converted dto.Cep and false.
+func AwesomeapiExtractCepFromBody(c *CepQuery, body []byte) (dto.Cep, bool) {
+	cepdto, err := dto.NewAwesomeapiFromJson(string(body))
+	if err != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
+		return dto.Cep{}, true
+	}
+	cep := dto.Cep{
+		Cep:          cepdto.Cep,
+		State:        cepdto.State,
+		City:         cepdto.City,
+		Neighborhood: cepdto.District,
+		Street:       cepdto.Address,
+		CityLevel:    cepdto.IsCityLevel(),
+	}
+	if lat, lon, ok := cepdto.Coordinates(); ok {
+		cep.Latitude = &lat
+		cep.Longitude = &lon
+	}
+	return cep, false
+}
+
+// AwesomeapiExtractErrorFromBody parses the JSON body AwesomeAPI sends with a 400 or 404 status
+// into a dto.UpstreamError carrying the upstream code and message.
+// It returns nil if the body is not an AwesomeAPI error payload.
+func AwesomeapiExtractErrorFromBody(c *CepQuery, statusCode int, body []byte) error {
+	errdto, err := dto.NewAwesomeapiErrorFromJson(string(body))
+	if err != nil {
+		return nil
+	}
+	return errdto.ToUpstreamError(c.ServiceName, statusCode)
+}
+
+// NewQueryAwesomeapi creates a new CepQuery instance configured to use the AwesomeAPI CEP service.
+// It sets up the context, cancel function, cep value, response channel, URL template, and service name.
+// It also assigns the AwesomeapiExtractCepFromBody function to handle the extraction of Cep information
+// from the response body, and AwesomeapiExtractErrorFromBody for error bodies.
+func NewQueryAwesomeapi(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
+	q := &CepQuery{
+		Context:     ctx,
+		Cancel:      cancel,
+		Cep:         cep,
+		Channel:     make(chan dto.Response, 1),
+		url:         "https://cep.awesomeapi.com.br/json/{{cep}}",
+		ServiceName: "Awesomeapi",
+	}
+	q.ExtractCepFromBody = AwesomeapiExtractCepFromBody
+	q.ExtractErrorFromBody = AwesomeapiExtractErrorFromBody
+	return q
+}
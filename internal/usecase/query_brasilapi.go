@@ -25,23 +25,36 @@ func BrasilapiExtractCepFromBody(c *CepQuery, body []byte) (dto.Cep, bool) {
 		City:         cepdto.City,
 		Neighborhood: cepdto.Neighborhood,
 		Street:       cepdto.Street,
+		CityLevel:    cepdto.IsCityLevel(),
 	}
 	return cep, false
 }
 
+// BrasilapiExtractErrorFromBody parses the JSON body Brasilapi sends with a 400 or 404 status
+// into a dto.UpstreamError carrying the upstream message and the failing sub-services.
+// It returns nil if the body is not a Brasilapi error payload.
+func BrasilapiExtractErrorFromBody(c *CepQuery, statusCode int, body []byte) error {
+	errdto, err := dto.NewBrasilapiErrorFromJson(string(body))
+	if err != nil {
+		return nil
+	}
+	return errdto.ToUpstreamError(c.ServiceName, statusCode)
+}
+
 // NewQueryBrasilapi creates a new CepQuery instance configured to use the Brasilapi service.
 // It sets up the context, cancel function, cep value, response channel, URL template, and service name.
 // It also assigns the BrasilapiExtractCepFromBody function to handle the extraction of Cep information
-// from the response body.
+// from the response body, and BrasilapiExtractErrorFromBody for error bodies.
 func NewQueryBrasilapi(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
 	q := &CepQuery{
 		Context:     ctx,
 		Cancel:      cancel,
 		Cep:         cep,
-		Channel:     make(chan dto.Response),
+		Channel:     make(chan dto.Response, 1),
 		url:         "https://brasilapi.com.br/api/cep/v1/{{cep}}",
 		ServiceName: "Brasilapi",
 	}
 	q.ExtractCepFromBody = BrasilapiExtractCepFromBody
+	q.ExtractErrorFromBody = BrasilapiExtractErrorFromBody
 	return q
 }
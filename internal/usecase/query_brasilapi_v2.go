@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// BrasilapiV2ExtractCepFromBody extracts a dto.Cep from the given byte slice, that is assumed to be a JSON
+// object from the Brasilapi v2 endpoint.
+// If the body is not a valid JSON or fails validation, it sends an error to the channel and returns an
+// empty Cep, and true.
+// Otherwise, it extracts the address fields and, when Brasilapi knows them, the latitude and longitude,
+// and returns the new Cep object, and false.
+func BrasilapiV2ExtractCepFromBody(c *CepQuery, body []byte) (dto.Cep, bool) {
+	cepdto, err := dto.NewBrasilapiV2FromJson(string(body))
+	if err != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
+		return dto.Cep{}, true
+	}
+	cep := dto.Cep{
+		Cep:          cepdto.Cep,
+		State:        cepdto.State,
+		City:         cepdto.City,
+		Neighborhood: cepdto.Neighborhood,
+		Street:       cepdto.Street,
+		CityLevel:    cepdto.IsCityLevel(),
+	}
+	if lat, lon, ok := cepdto.Coordinates(); ok {
+		cep.Latitude = &lat
+		cep.Longitude = &lon
+	}
+	return cep, false
+}
+
+// NewQueryBrasilapiV2 creates a new CepQuery instance configured to use the Brasilapi v2 service,
+// which also returns the geolocation of the cep.
+// It sets up the context, cancel function, cep value, response channel, URL template, and service name.
+// It also assigns the BrasilapiV2ExtractCepFromBody function to handle the extraction of Cep information
+// from the response body. Error bodies have the same format as in v1, so BrasilapiExtractErrorFromBody
+// handles them.
+func NewQueryBrasilapiV2(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
+	q := &CepQuery{
+		Context:     ctx,
+		Cancel:      cancel,
+		Cep:         cep,
+		Channel:     make(chan dto.Response, 1),
+		url:         "https://brasilapi.com.br/api/cep/v2/{{cep}}",
+		ServiceName: "BrasilapiV2",
+	}
+	q.ExtractCepFromBody = BrasilapiV2ExtractCepFromBody
+	q.ExtractErrorFromBody = BrasilapiExtractErrorFromBody
+	return q
+}
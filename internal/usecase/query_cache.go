@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/cache"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// CacheProviderName is the name the cache provider is registered under.
+const CacheProviderName = "cache"
+
+// resultCache is the cache registered with RegisterCache, which stores the winners of the races.
+var resultCache *cache.Cache
+
+// NewQueryCache creates a new CepQuery instance that resolves the cep from the
+// given cache instead of an HTTP service.
+// It sets up the context, cancel function, cep value, response channel and service name,
+// and a Lookup function that answers dto.ErrNotFound for a cep that is not cached.
+func NewQueryCache(ctx context.Context, cancel context.CancelFunc, cep string, c *cache.Cache) *CepQuery {
+	q := &CepQuery{
+		Context:     ctx,
+		Cancel:      cancel,
+		Cep:         cep,
+		Channel:     make(chan dto.Response, 1),
+		ServiceName: "Cache",
+	}
+	q.Lookup = func(q *CepQuery) (dto.Cep, error) {
+		found, ok := c.Get(q.Cep)
+		if !ok {
+			return dto.Cep{}, dto.ErrNotFound
+		}
+		return found, nil
+	}
+	return q
+}
+
+// RegisterCache registers the given cache as the "cache" provider, so it can be
+// raced with the other providers, and makes ExecuteQueries store every winner in it.
+func RegisterCache(c *cache.Cache) {
+	resultCache = c
+	RegisterProvider(CacheProviderName, func(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
+		return NewQueryCache(ctx, cancel, cep, c)
+	})
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dataset"
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// DatasetProviderName is the name the local dataset provider is registered under.
+const DatasetProviderName = "dataset"
+
+// NewQueryDataset creates a new CepQuery instance that resolves the cep from the
+// given in-memory dataset instead of an HTTP service.
+// It sets up the context, cancel function, cep value, response channel and service name,
+// and a Lookup function that answers dto.ErrNotFound for a cep outside the dataset.
+func NewQueryDataset(ctx context.Context, cancel context.CancelFunc, cep string, ds *dataset.Dataset) *CepQuery {
+	q := &CepQuery{
+		Context:     ctx,
+		Cancel:      cancel,
+		Cep:         cep,
+		Channel:     make(chan dto.Response, 1),
+		ServiceName: "Dataset",
+	}
+	q.Lookup = func(c *CepQuery) (dto.Cep, error) {
+		found, ok := ds.Lookup(c.Cep)
+		if !ok {
+			return dto.Cep{}, dto.ErrNotFound
+		}
+		return found, nil
+	}
+	return q
+}
+
+// RegisterDataset registers the given dataset as the "dataset" provider,
+// so it can be raced with the HTTP providers.
+func RegisterDataset(ds *dataset.Dataset) {
+	RegisterProvider(DatasetProviderName, func(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
+		return NewQueryDataset(ctx, cancel, cep, ds)
+	})
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/antoniofmoliveira/fullcycle-multithreading/internal/dto"
+)
+
+// PostmonExtractCepFromBody takes a *CepQuery and a JSON body, attempts to parse it as a dto.Postmon,
+// and if successful, converts it to a dto.Cep.
+// If the parsing fails, it sends an error to the query's channel and returns an empty dto.Cep and true.
+// Otherwise, it returns the converted dto.Cep and false.
+func PostmonExtractCepFromBody(c *CepQuery, body []byte) (dto.Cep, bool) {
+	cepdto, err := dto.NewPostmonFromJson(string(body))
+	if err != nil {
+		c.Channel <- dto.NewResponse(dto.Cep{}, err)
+		return dto.Cep{}, true
+	}
+	cep := dto.Cep{
+		Cep:          cepdto.Cep,
+		State:        cepdto.Estado,
+		City:         cepdto.Cidade,
+		Neighborhood: cepdto.Bairro,
+		Street:       cepdto.Logradouro,
+		CityLevel:    cepdto.IsCityLevel(),
+	}
+	return cep, false
+}
+
+// NewQueryPostmon creates a new CepQuery instance configured to use the Postmon service.
+// Postmon answers 404 with an empty body, so the generic error for the status is used.
+// It sets up the context, cancel function, cep value, response channel, URL template, and service name.
+// It also assigns the PostmonExtractCepFromBody function to handle the extraction of Cep information
+// from the response body.
+func NewQueryPostmon(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
+	q := &CepQuery{
+		Context:     ctx,
+		Cancel:      cancel,
+		Cep:         cep,
+		Channel:     make(chan dto.Response, 1),
+		url:         "https://api.postmon.com.br/v1/cep/{{cep}}",
+		ServiceName: "Postmon",
+	}
+	q.ExtractCepFromBody = PostmonExtractCepFromBody
+	return q
+}
@@ -22,23 +22,36 @@ func ViacepExtractCepFromBody(c *CepQuery, body []byte) (dto.Cep, bool) {
 		City:         cepdto.Localidade,
 		Neighborhood: cepdto.Bairro,
 		Street:       cepdto.Logradouro,
+		CityLevel:    cepdto.IsCityLevel(),
 	}
 	return cep, false
 }
 
+// ViacepExtractErrorFromBody parses the HTML page ViaCEP sends with a 400 status
+// into a dto.UpstreamError carrying the messages of the page.
+// It returns nil if the body is not a ViaCEP error page.
+func ViacepExtractErrorFromBody(c *CepQuery, statusCode int, body []byte) error {
+	errdto, err := dto.NewViacepErrorFromHtml(string(body))
+	if err != nil {
+		return nil
+	}
+	return errdto.ToUpstreamError(c.ServiceName, statusCode)
+}
+
 // NewCepQueryViacep creates a new CepQuery instance configured to use the ViaCEP service.
 // It sets up the context, cancel function, cep value, response channel, URL template, and service name.
 // It also assigns the ViacepExtractCepFromBody function to handle the extraction of Cep information
-// from the response body.
+// from the response body, and ViacepExtractErrorFromBody for error pages.
 func NewCepQueryViacep(ctx context.Context, cancel context.CancelFunc, cep string) *CepQuery {
 	q := &CepQuery{
 		Context:     ctx,
 		Cancel:      cancel,
 		Cep:         cep,
-		Channel:     make(chan dto.Response),
+		Channel:     make(chan dto.Response, 1),
 		url:         "http://viacep.com.br/ws/{{cep}}/json/",
 		ServiceName: "Viacep",
 	}
 	q.ExtractCepFromBody = ViacepExtractCepFromBody
+	q.ExtractErrorFromBody = ViacepExtractErrorFromBody
 	return q
 }
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RaceOptions configures how ExecuteQueries races the providers.
+//
+// By default every provider is equal and the first valid response wins. Giving
+// some providers a higher priority, and a GraceWindow, trades a little latency
+// for data quality: after the first valid response arrives, the race keeps
+// waiting up to GraceWindow for a pending provider that ranks higher.
+type RaceOptions struct {
+	// Providers are the names of the providers to race; DefaultProviders if empty.
+	Providers []string
+	// GraceWindow is how long to wait, after the first valid response, for a
+	// better ranked provider. Zero keeps the first valid response.
+	GraceWindow time.Duration
+	// Priorities rank providers by name; a higher priority is preferred.
+	// Providers not listed have priority 0.
+	Priorities map[string]int
+	// Weights are the trust weights of the providers, from 0 to 1, which rank
+	// providers of equal priority. Providers not listed have weight 1.
+	Weights map[string]float64
+}
+
+// rank is the position of a provider in the preference order.
+type rank struct {
+	priority int
+	weight   float64
+}
+
+// rankOf returns the rank of the named provider.
+func (o RaceOptions) rankOf(provider string) rank {
+	r := rank{priority: o.Priorities[provider], weight: 1}
+	if w, ok := o.Weights[provider]; ok {
+		r.weight = w
+	}
+	return r
+}
+
+// better reports whether rank a is preferred over rank b.
+func (a rank) better(b rank) bool {
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.weight > b.weight
+}
+
+// ParsePriorities parses a comma-separated list of provider=priority pairs,
+// such as "dataset=10,brasilapi-v2=5".
+// It returns an error if a pair is malformed, a priority is not an integer or
+// a provider is unknown.
+func ParsePriorities(list string) (map[string]int, error) {
+	priorities := map[string]int{}
+	err := parsePairs(list, func(provider, value string) error {
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("priority of " + provider + " must be an integer")
+		}
+		priorities[provider] = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return priorities, nil
+}
+
+// ParseWeights parses a comma-separated list of provider=weight pairs,
+// such as "viacep=0.8,postmon=0.5".
+// It returns an error if a pair is malformed, a weight is not a number between
+// 0 and 1 or a provider is unknown.
+func ParseWeights(list string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	err := parsePairs(list, func(provider, value string) error {
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil || w < 0 || w > 1 {
+			return errors.New("weight of " + provider + " must be a number between 0 and 1")
+		}
+		weights[provider] = w
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+// parsePairs splits list into provider=value pairs and calls set for each one,
+// with the provider name lower-cased and checked against the registered providers.
+func parsePairs(list string, set func(provider, value string) error) error {
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		provider, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return errors.New("malformed pair " + pair + ", want provider=value")
+		}
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if _, ok := providers[provider]; !ok {
+			return errors.New("unknown provider " + provider + ", available providers: " + strings.Join(Providers(), ", "))
+		}
+		if err := set(provider, strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
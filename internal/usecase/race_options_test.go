@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePriorities(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "parse priorities", list: "brasilapi-v2=5, ViaCEP=-1", want: map[string]int{"brasilapi-v2": 5, "viacep": -1}},
+		{name: "parse priorities empty", list: "", want: map[string]int{}},
+		{name: "parse priorities not an integer", list: "viacep=high", wantErr: true},
+		{name: "parse priorities malformed", list: "viacep", wantErr: true},
+		{name: "parse priorities unknown provider", list: "correios=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePriorities(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePriorities() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePriorities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{name: "parse weights", list: "viacep=0.8,postmon=0", want: map[string]float64{"viacep": 0.8, "postmon": 0}},
+		{name: "parse weights above one", list: "viacep=1.5", wantErr: true},
+		{name: "parse weights not a number", list: "viacep=much", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWeights(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseWeights() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWeights() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRaceOptions_rankOf(t *testing.T) {
+	opts := RaceOptions{
+		Priorities: map[string]int{"dataset": 10, "brasilapi-v2": 5},
+		Weights:    map[string]float64{"viacep": 0.5},
+	}
+	if !opts.rankOf("dataset").better(opts.rankOf("brasilapi-v2")) {
+		t.Errorf("dataset must rank higher than brasilapi-v2")
+	}
+	if !opts.rankOf("postmon").better(opts.rankOf("viacep")) {
+		t.Errorf("postmon, with the default weight, must rank higher than viacep")
+	}
+	if opts.rankOf("postmon").better(opts.rankOf("opencep")) {
+		t.Errorf("providers with default priority and weight must rank equal")
+	}
+}